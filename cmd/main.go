@@ -8,10 +8,17 @@ import (
 	"os/signal"
 	"rest_clickhouse/cmd/providers"
 	"rest_clickhouse/configs"
+	"rest_clickhouse/internal/infrastructure/apikey"
+	"rest_clickhouse/internal/infrastructure/graphql"
 	goods_service "rest_clickhouse/internal/infrastructure/http"
 	eventQueue "rest_clickhouse/internal/infrastructure/queue/nats"
 	repository "rest_clickhouse/internal/infrastructure/repository"
 	"rest_clickhouse/internal/infrastructure/usecase/interactors"
+	usecaseRepository "rest_clickhouse/internal/infrastructure/usecase/repository"
+	"rest_clickhouse/internal/infrastructure/webhook"
+	"rest_clickhouse/pkg/features"
+	"rest_clickhouse/pkg/lifecycle"
+	"rest_clickhouse/pkg/ratelimit"
 	"syscall"
 
 	"github.com/joho/godotenv"
@@ -28,11 +35,12 @@ func run() error {
 		return fmt.Errorf("failed to load .env file: %w", err)
 	}
 
-	cnf, err := configs.LoadConfig()
+	cnf, err := configs.LoadConfig(os.Args[1:])
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
+	// ctx - время жизни фоновых компонентов, он отменяется после упорядоченной остановки.
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -41,48 +49,239 @@ func run() error {
 		return fmt.Errorf("failed to provide console logger: %w", err)
 	}
 
+	// Подсистемы регистрируются после своих зависимостей и останавливаются в обратном порядке:
+	// перестаем принимать HTTP и gRPC, дожидаемся запросов, вычитываем NATS, сбрасываем пачку
+	// событий и только потом закрываем Clickhouse, Redis и Postgres.
+	app := lifecycle.New(logger)
+	defer func() {
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), cnf.Shutdown.Timeout)
+		defer cancelShutdown()
+
+		if err := app.Stop(shutdownCtx); err != nil {
+			logger.ErrorF("shutdown finished with errors: %v", err)
+		}
+	}()
+
+	shutdownTracing, err := providers.ProvideTracing(ctx, cnf)
+	if err != nil {
+		return fmt.Errorf("failed to provide tracing: %w", err)
+	}
+	app.Append(lifecycle.Hook{Name: "tracing", Stop: shutdownTracing})
+
+	metricsServer := providers.ProvideMetricsServer(cnf, logger)
+	app.Append(lifecycle.Hook{
+		Name: "metrics server",
+		Start: func(context.Context) error {
+			return metricsServer.Start()
+		},
+		Stop: func(ctx context.Context) error {
+			metricsServer.Stop(ctx)
+			return nil
+		},
+	})
+
 	db, closeDB, err := providers.ProvidePostgres(ctx, cnf, logger)
 	if err != nil {
 		return fmt.Errorf("failed to provide postgres: %w", err)
 	}
+	app.Append(lifecycle.Hook{
+		Name: "postgres",
+		Stop: func(context.Context) error {
+			closeDB()
+			return nil
+		},
+	})
 
 	redisClient, err := providers.ProvideRedis(cnf)
 	if err != nil {
 		return fmt.Errorf("failed to provide redis client: %w", err)
 	}
+	app.Append(lifecycle.Hook{
+		Name: "redis",
+		Stop: func(context.Context) error {
+			return redisClient.Close()
+		},
+	})
+
+	clickHouseConn, err := providers.ProvideClickhouse(cnf)
+	if err != nil {
+		return fmt.Errorf("failed to provide clickhouse: %w", err)
+	}
+	app.Append(lifecycle.Hook{
+		Name: "clickhouse",
+		Stop: func(context.Context) error {
+			return clickHouseConn.Close()
+		},
+	})
 
 	queue, err := providers.ProvideQueue(cnf)
 	if err != nil {
 		return fmt.Errorf("failed to provide queue: %w", err)
 	}
+	app.Append(lifecycle.Hook{Name: "nats", Stop: queue.Drain})
+
+	timeouts := providers.ProvideTimeouts(cnf)
+	cacheSettings := interactors.NewCacheSettings(cnf.Cache.GoodsListTTL)
+	eventsBatch := usecaseRepository.NewEventsBatchSettings(cnf.ClickHouse.BatchSize, cnf.ClickHouse.FlushInterval)
+	featureFlags := features.NewFlags(cnf.Features.GoodsListCache, cnf.Features.GoodsStream)
+
+	attributesRepository := repository.NewAttributesRepository(db, logger)
+	attributesInteractor := interactors.NewAttributesInteractor(attributesRepository, timeouts, logger)
+	attributesService := goods_service.NewAttributesService(attributesInteractor, logger)
 
 	goodsRepository := repository.NewGoodsRepository(ctx, db, redisClient, logger)
-	goodsInteractor := interactors.NewGoodsInteractor(goodsRepository, redisClient, queue, logger)
+	goodsInteractor := interactors.NewGoodsInteractor(goodsRepository, attributesRepository, redisClient, queue, cacheSettings, featureFlags, timeouts, logger)
 	goodService := goods_service.NewGoodsService(goodsInteractor, logger)
 
-	clickHouseConn := providers.ProvideClickhouse(cnf)
-	logRepo := repository.NewLogsRepository(clickHouseConn, logger)
-	eventListener := eventQueue.NewEventListener(ctx, queue, logRepo, logger)
-	go eventListener.ListenTopic()
+	categoriesRepository := repository.NewCategoriesRepository(db, logger)
+	categoriesInteractor := interactors.NewCategoriesInteractor(categoriesRepository, timeouts, logger)
+	categoriesService := goods_service.NewCategoriesService(categoriesInteractor, logger)
 
-	server := providers.ProvideHTTPServer(cnf, goodService, logger)
+	logRepo := repository.NewLogsRepository(clickHouseConn, eventsBatch, logger)
+	eventListener := eventQueue.NewEventListener(ctx, queue, logRepo, eventsBatch, logger)
+	app.Append(lifecycle.Hook{
+		Name: "events listener",
+		Start: func(context.Context) error {
+			return eventListener.ListenTopic()
+		},
+		Stop: eventListener.Stop,
+	})
 
-	go func() {
-		sigs := make(chan os.Signal, 1)
-		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
-		<-sigs
-		fmt.Println("Terminating the app")
-		fmt.Println("Shutdown workers")
-		cancel()
+	webhooksRepository := repository.NewWebhooksRepository(db, logger)
+	webhookDispatcher := webhook.NewDispatcher(ctx, queue, webhooksRepository, webhook.NewHTTPClient(cnf.Webhooks.Timeout), logger)
+	app.Append(lifecycle.Hook{
+		Name: "webhook dispatcher",
+		Start: func(context.Context) error {
+			return webhookDispatcher.Start()
+		},
+		Stop: webhookDispatcher.Stop,
+	})
+	webhooksInteractor := interactors.NewWebhooksInteractor(webhooksRepository, webhookDispatcher, timeouts, logger)
+	webhooksService := goods_service.NewWebhooksService(webhooksInteractor, logger)
 
-		fmt.Println("Close DB")
-		closeDB()
+	apiKeysRepository := repository.NewApiKeysRepository(db, logger)
+	apiKeyUsage := apikey.NewUsageRecorder(apiKeysRepository, cnf.Auth.ApiKeys.LastUsedFlushInterval, logger)
+	app.Append(lifecycle.Hook{
+		Name: "api key usage",
+		Start: func(context.Context) error {
+			apiKeyUsage.Start()
+			return nil
+		},
+		Stop: apiKeyUsage.Stop,
+	})
+	apiKeysInteractor := interactors.NewApiKeysInteractor(apiKeysRepository, redisClient, apiKeyUsage, cnf.Auth.ApiKeys.CacheTTL, timeouts, logger)
+	apiKeysService := goods_service.NewApiKeysService(apiKeysInteractor, logger)
 
-		fmt.Println("Stop Server")
-		server.Stop(ctx)
-	}()
+	auditRepository := repository.NewAuditRepository(clickHouseConn, logger)
+	auditInteractor := interactors.NewAuditInteractor(auditRepository, timeouts, logger)
+	auditService := goods_service.NewAuditService(auditInteractor, logger)
+
+	eventBroadcaster := eventQueue.NewEventBroadcaster(queue, cnf.Stream.BufferSize, logger)
+	streamService := goods_service.NewGoodsStreamService(eventBroadcaster, featureFlags, logger)
+
+	projectsRepository := repository.NewProjectsRepository(db, logger)
+	projectsInteractor := interactors.NewProjectsInteractor(projectsRepository, goodsRepository, logRepo, timeouts, logger)
+	graphQLServer, err := graphql.NewServer(goodsInteractor, projectsInteractor, eventBroadcaster, featureFlags, logger)
+	if err != nil {
+		return fmt.Errorf("failed to provide graphql server: %w", err)
+	}
+	graphQLService := goods_service.NewGraphQLService(graphQLServer, logger)
+
+	healthChecker := providers.ProvideHealthChecker(cnf, db, redisClient, queue, clickHouseConn)
+	healthService := goods_service.NewHealthService(healthChecker, logger)
+
+	jwtVerifier, err := providers.ProvideJWTVerifier(cnf)
+	if err != nil {
+		return fmt.Errorf("failed to provide jwt verifier: %w", err)
+	}
+	if jwtVerifier == nil {
+		logger.Warn("Authentication is disabled, any caller can access any project")
+	}
+
+	grpcServer := providers.ProvideGRPCServer(cnf, goodsInteractor, queue, jwtVerifier, apiKeysInteractor, logger)
+	app.Append(lifecycle.Hook{
+		Name: "grpc server",
+		Start: func(context.Context) error {
+			return grpcServer.Start()
+		},
+		Stop: func(ctx context.Context) error {
+			grpcServer.Stop(ctx)
+			return nil
+		},
+	})
+
+	rateLimitPolicy, err := providers.ProvideRateLimitPolicy(cnf)
+	if err != nil {
+		return fmt.Errorf("failed to provide rate limit policy: %w", err)
+	}
+	rateLimits := ratelimit.NewSettings(rateLimitPolicy)
+	rateLimiter := providers.ProvideRateLimiter(redisClient, logger)
+
+	server, err := providers.ProvideHTTPServer(cnf, goodService, categoriesService, attributesService, streamService, webhooksService, apiKeysService, auditService, graphQLService, healthService, jwtVerifier, apiKeysInteractor, rateLimiter, rateLimits, logger)
+	if err != nil {
+		return fmt.Errorf("failed to provide http server: %w", err)
+	}
+	app.Append(lifecycle.Hook{
+		Name: "http server",
+		Start: func(context.Context) error {
+			return server.Start()
+		},
+		Stop: func(ctx context.Context) error {
+			server.Stop(ctx)
+			return nil
+		},
+	})
+
+	// Рассылка SSE останавливается раньше HTTP сервера: иначе открытые потоки не дадут ему дождаться запросов.
+	app.Append(lifecycle.Hook{
+		Name: "goods stream",
+		Start: func(context.Context) error {
+			return eventBroadcaster.Start()
+		},
+		Stop: eventBroadcaster.Stop,
+	})
+
+	// Перезагружаемые настройки применяются к уже работающим компонентам через атомарные значения.
+	configWatcher := configs.NewWatcher(cnf, os.Args[1:], logger)
+	configWatcher.OnReload(func(cfg *configs.Config) {
+		if err := logger.SetLevel(cfg.Logger.Level); err != nil {
+			logger.ErrorF("error setting log level: %v", err)
+		}
+		cacheSettings.SetGoodsListTTL(cfg.Cache.GoodsListTTL)
+		eventsBatch.SetSize(cfg.ClickHouse.BatchSize)
+		eventsBatch.SetFlushInterval(cfg.ClickHouse.FlushInterval)
+		featureFlags.SetGoodsListCache(cfg.Features.GoodsListCache)
+		featureFlags.SetGoodsStream(cfg.Features.GoodsStream)
+		if policy, err := providers.ProvideRateLimitPolicy(cfg); err != nil {
+			logger.ErrorF("error applying rate limits: %v", err)
+		} else {
+			rateLimits.Store(policy)
+		}
+	})
+	app.Append(lifecycle.Hook{
+		Name: "config watcher",
+		Start: func(context.Context) error {
+			return configWatcher.Start()
+		},
+		Stop: configWatcher.Stop,
+	})
+
+	app.Append(lifecycle.Hook{
+		Name: "readiness",
+		Stop: func(context.Context) error {
+			healthChecker.SetShuttingDown()
+			return nil
+		},
+	})
+
+	if err := app.Start(ctx); err != nil {
+		return err
+	}
 
-	server.Start()
+	signalCtx, stopSignals := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+	<-signalCtx.Done()
+	logger.Info("Terminating the app")
 
 	return nil
 }
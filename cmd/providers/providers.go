@@ -6,23 +6,120 @@ import (
 	"fmt"
 	"os"
 	"rest_clickhouse/configs"
+	grpc_server "rest_clickhouse/internal/infrastructure/grpc"
 	"rest_clickhouse/internal/infrastructure/http"
 	goods_service "rest_clickhouse/internal/infrastructure/http"
 	"rest_clickhouse/internal/infrastructure/queue"
 	nats_client "rest_clickhouse/internal/infrastructure/queue/nats"
+	"rest_clickhouse/internal/infrastructure/usecase/interactors"
+	"rest_clickhouse/pkg/auth"
 	postgres "rest_clickhouse/pkg/db"
+	"rest_clickhouse/pkg/health"
 	"rest_clickhouse/pkg/logger"
 	"rest_clickhouse/pkg/logger/zerolog"
+	"rest_clickhouse/pkg/metrics"
+	"rest_clickhouse/pkg/ratelimit"
+	"rest_clickhouse/pkg/tracing"
 	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
 
 	"github.com/go-redis/redis"
 	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
-func ProvideHTTPServer(config *configs.Config, goodsService goods_service.GoodsService, logger logger.Logger) http.HTTPServer {
-	return http.NewEchoHTTPServer(config.HttpServer.Port, goodsService, logger)
+func ProvideHTTPServer(
+	config *configs.Config,
+	goodsService goods_service.GoodsService,
+	categoriesService goods_service.CategoriesService,
+	attributesService goods_service.AttributesService,
+	streamService goods_service.GoodsStreamService,
+	webhooksService goods_service.WebhooksService,
+	apiKeysService goods_service.ApiKeysService,
+	auditService goods_service.AuditService,
+	graphQLService goods_service.GraphQLService,
+	healthService goods_service.HealthService,
+	verifier *auth.JWTVerifier,
+	apiKeysInteractor interactors.ApiKeysInteractor,
+	rateLimiter ratelimit.Limiter,
+	rateLimits *ratelimit.Settings,
+	logger logger.Logger,
+) (http.HTTPServer, error) {
+	middlewareConfig := http.MiddlewareConfig{
+		RequestIdHeader: config.HttpServer.RequestIdHeader,
+		AccessLog:       config.HttpServer.AccessLog,
+		BodyLimit:       config.HttpServer.BodyLimit,
+		RecoverStack:    config.HttpServer.RecoverStack,
+		Auth:            verifier,
+		ApiKeys:         apiKeysInteractor,
+		RateLimiter:     rateLimiter,
+		RateLimits:      rateLimits,
+	}
+
+	server, err := http.NewEchoHTTPServer(config.HttpServer.Port, middlewareConfig, goodsService, categoriesService, attributesService, streamService, webhooksService, apiKeysService, auditService, graphQLService, healthService, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return server, nil
+}
+
+// ProvideJWTVerifier возвращает nil, если аутентификация отключена.
+func ProvideJWTVerifier(cnf *configs.Config) (*auth.JWTVerifier, error) {
+	if !cnf.Auth.Enabled {
+		return nil, nil
+	}
+
+	return auth.NewJWTVerifier(auth.Config{
+		Algorithm:     cnf.Auth.JWT.Algorithm,
+		Secret:        cnf.Auth.JWT.Secret,
+		PublicKeyFile: cnf.Auth.JWT.PublicKeyFile,
+		JWKSFile:      cnf.Auth.JWT.JWKSFile,
+		Issuer:        cnf.Auth.JWT.Issuer,
+		Audience:      cnf.Auth.JWT.Audience,
+	})
+}
+
+func ProvideRateLimiter(redisClient *redis.Client, logger logger.Logger) ratelimit.Limiter {
+	return ratelimit.NewFallbackLimiter(ratelimit.NewRedisLimiter(redisClient, "rateLimit-"), ratelimit.NewMemoryLimiter(), logger)
+}
+
+// ProvideRateLimitPolicy разбирает лимиты из конфигурации, они уже проверены при ее загрузке.
+func ProvideRateLimitPolicy(cnf *configs.Config) (ratelimit.Policy, error) {
+	policy := ratelimit.Policy{Enabled: cnf.RateLimit.Enabled}
+
+	var err error
+	if policy.Client, err = ratelimit.ParseLimit(cnf.RateLimit.Client); err != nil {
+		return ratelimit.Policy{}, err
+	}
+	if policy.Project, err = ratelimit.ParseLimit(cnf.RateLimit.Project); err != nil {
+		return ratelimit.Policy{}, err
+	}
+	if policy.Routes, err = ratelimit.ParseRules(cnf.RateLimit.Routes); err != nil {
+		return ratelimit.Policy{}, err
+	}
+	if policy.Overrides, err = ratelimit.ParseRules(cnf.RateLimit.Overrides); err != nil {
+		return ratelimit.Policy{}, err
+	}
+
+	return policy, nil
+}
+
+func ProvideGRPCServer(
+	config *configs.Config,
+	goodsInteractor interactors.GoodsInteractor,
+	sub queue.Subscriber,
+	verifier *auth.JWTVerifier,
+	apiKeysInteractor interactors.ApiKeysInteractor,
+	logger logger.Logger,
+) grpc_server.GRPCServer {
+	goodsServer := grpc_server.NewGoodsServer(goodsInteractor, sub, logger)
+	return grpc_server.NewGoodsGRPCServer(config.GrpcServer.Port, goodsServer, verifier, apiKeysInteractor, logger)
+}
+
+func ProvideMetricsServer(config *configs.Config, logger logger.Logger) http.HTTPServer {
+	return http.NewMetricsHTTPServer(config.HttpServer.MetricsPort, logger)
 }
 
 func ProvidePostgres(ctx context.Context, cnf *configs.Config, logger logger.Logger) (*postgres.DB, func(), error) {
@@ -31,6 +128,10 @@ func ProvidePostgres(ctx context.Context, cnf *configs.Config, logger logger.Log
 		return nil, nil, err
 	}
 
+	if err := prometheus.Register(metrics.NewPgxPoolCollector(repo.Pool)); err != nil {
+		return nil, nil, fmt.Errorf("error registering pgx pool collector: %w", err)
+	}
+
 	closer := func() {
 		repo.Close()
 	}
@@ -38,54 +139,100 @@ func ProvidePostgres(ctx context.Context, cnf *configs.Config, logger logger.Log
 	return repo, closer, nil
 }
 
+func ProvideHealthChecker(cnf *configs.Config, db *postgres.DB, redisClient *redis.Client, queue queue.Pinger, clickHouseConn *sql.DB) *health.Checker {
+	checker := health.NewChecker(cnf.Health.CheckTimeout)
+	checker.Register("postgres", db.Ping)
+	checker.Register("redis", func(ctx context.Context) error {
+		return redisClient.WithContext(ctx).Ping().Err()
+	})
+	checker.Register("nats", queue.Ping)
+	checker.Register("clickhouse", clickHouseConn.PingContext)
+
+	return checker
+}
+
+func ProvideTimeouts(cnf *configs.Config) interactors.Timeouts {
+	return interactors.Timeouts{
+		Read:   cnf.Timeouts.Read,
+		Write:  cnf.Timeouts.Write,
+		Search: cnf.Timeouts.Search,
+	}
+}
+
+func ProvideTracing(ctx context.Context, cnf *configs.Config) (func(ctx context.Context) error, error) {
+	return tracing.NewProvider(ctx, tracing.Config{
+		ServiceName:  cnf.Tracing.ServiceName,
+		Exporter:     cnf.Tracing.Exporter,
+		FilePath:     cnf.Tracing.FilePath,
+		OTLPEndpoint: cnf.Tracing.OTLPEndpoint,
+		SampleRatio:  cnf.Tracing.SampleRatio,
+	})
+}
+
 func ProvideConsoleLogger(cnf *configs.Config) (logger.Logger, error) {
-	return zerolog.NewZeroLog(os.Stderr)
+	return zerolog.NewZeroLog(os.Stderr, zerolog.Config{
+		Level:       cnf.Logger.Level,
+		Format:      cnf.Logger.Format,
+		SampleEvery: cnf.Logger.SampleEvery,
+	})
 }
 
 func ProvideRedis(cnf *configs.Config) (*redis.Client, error) {
 	client := redis.NewClient(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%s", cnf.Redis.Host, cnf.Redis.Port),
-		Password: "",
-		DB:       0,
+		Password: cnf.Redis.Password,
+		DB:       cnf.Redis.DB,
 	})
 	_, err := client.Ping().Result()
 	return client, err
 }
 
 func ProvideQueue(cnf *configs.Config) (queue.PubSub, error) {
-	nc, err := nats.Connect(fmt.Sprintf("nats://%s:4222", os.Getenv("NATS_HOST")))
+	options := make([]nats.Option, 0, 2)
+	if cnf.Nats.User != "" {
+		options = append(options, nats.UserInfo(cnf.Nats.User, cnf.Nats.Password))
+	}
+	if cnf.Nats.Token != "" {
+		options = append(options, nats.Token(cnf.Nats.Token))
+	}
+
+	nc, err := nats.Connect(cnf.Nats.URL, options...)
 	if err != nil {
 		return nil, err
 	}
 
-	nats_client := nats_client.NewNatsClient(nc)
+	nats_client := nats_client.NewNatsClient(nc, map[string]string{
+		nats_client.EventTopicName: cnf.Nats.Subjects.Events,
+	})
 
 	return nats_client, nil
 }
 
-func ProvideClickhouse(cnf *configs.Config) *sql.DB {
-	conn := clickhouse.OpenDB(&clickhouse.Options{
-		Addr: []string{"127.0.0.1:9000"},
-		Auth: clickhouse.Auth{
-			Database: "default",
-			Username: "",
-			Password: "",
-		},
-
-		Settings: clickhouse.Settings{
-			"max_execution_time": 60,
-		},
-		DialTimeout: time.Second * 30,
-		Compression: &clickhouse.Compression{
+func ProvideClickhouse(cnf *configs.Config) (*sql.DB, error) {
+	options, err := clickhouse.ParseDSN(cnf.ClickHouse.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing clickhouse dsn: %w", err)
+	}
+
+	if options.Settings == nil {
+		options.Settings = clickhouse.Settings{}
+	}
+	if _, ok := options.Settings["max_execution_time"]; !ok {
+		options.Settings["max_execution_time"] = 60
+	}
+	options.DialTimeout = cnf.ClickHouse.DialTimeout
+	if options.Compression == nil {
+		options.Compression = &clickhouse.Compression{
 			Method: clickhouse.CompressionLZ4,
-		},
-		Debug:                false,
-		BlockBufferSize:      10,
-		MaxCompressionBuffer: 10240,
-	})
-	conn.SetMaxIdleConns(5)
-	conn.SetMaxOpenConns(10)
+		}
+	}
+	options.BlockBufferSize = 10
+	options.MaxCompressionBuffer = 10240
+
+	conn := clickhouse.OpenDB(options)
+	conn.SetMaxIdleConns(cnf.ClickHouse.MaxIdleConns)
+	conn.SetMaxOpenConns(cnf.ClickHouse.MaxOpenConns)
 	conn.SetConnMaxLifetime(time.Hour)
 
-	return conn
+	return conn, nil
 }
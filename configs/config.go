@@ -1,61 +1,122 @@
 package configs
 
-import (
-	"os"
-	"sync"
-)
+import "time"
 
+// Config описывает настройки всех подсистем. Для каждого поля задаются ключ в YAML файле,
+// переменная окружения и значение по умолчанию; флаг командной строки называется по пути
+// ключа в YAML, например -http.port. Поля с тегом reload применяются без перезапуска.
 type Config struct {
-	once       sync.Once
 	HttpServer struct {
-		Port        string
-		MetricsPort string
-	}
+		Port            string `yaml:"port" env:"HTTP_ADDR" default:"8080"`
+		MetricsPort     string `yaml:"metricsPort" env:"METRICS_PORT" default:"3030"`
+		RequestIdHeader string `yaml:"requestIdHeader" env:"HTTP_REQUEST_ID_HEADER" default:"X-Request-ID"`
+		AccessLog       bool   `yaml:"accessLog" env:"HTTP_ACCESS_LOG" default:"true"`
+		BodyLimit       string `yaml:"bodyLimit" env:"HTTP_BODY_LIMIT" default:"1M"`
+		RecoverStack    bool   `yaml:"recoverStack" env:"HTTP_RECOVER_STACK" default:"true"`
+	} `yaml:"http"`
+
+	GrpcServer struct {
+		Port string `yaml:"port" env:"GRPC_ADDR" default:"9090"`
+	} `yaml:"grpc"`
+
+	Auth struct {
+		// Enabled - требовать JWT на всех маршрутах, кроме проб.
+		Enabled bool `yaml:"enabled" env:"AUTH_ENABLED" default:"true"`
+		JWT     struct {
+			Algorithm     string `yaml:"algorithm" env:"AUTH_JWT_ALGORITHM" default:"HS256"`
+			Secret        string `yaml:"secret" env:"AUTH_JWT_SECRET"`
+			PublicKeyFile string `yaml:"publicKeyFile" env:"AUTH_JWT_PUBLIC_KEY_FILE"`
+			JWKSFile      string `yaml:"jwksFile" env:"AUTH_JWT_JWKS_FILE"`
+			Issuer        string `yaml:"issuer" env:"AUTH_JWT_ISSUER"`
+			Audience      string `yaml:"audience" env:"AUTH_JWT_AUDIENCE"`
+		} `yaml:"jwt"`
+		ApiKeys struct {
+			CacheTTL              time.Duration `yaml:"cacheTTL" env:"AUTH_API_KEYS_CACHE_TTL" default:"5m"`
+			LastUsedFlushInterval time.Duration `yaml:"lastUsedFlushInterval" env:"AUTH_API_KEYS_LAST_USED_FLUSH_INTERVAL" default:"30s"`
+		} `yaml:"apiKeys"`
+	} `yaml:"auth"`
+
+	// RateLimit - лимиты в формате <запросов>/<окно>, правила через ";", см. README.
+	RateLimit struct {
+		Enabled   bool   `yaml:"enabled" env:"RATE_LIMIT_ENABLED" default:"true" reload:"true"`
+		Client    string `yaml:"client" env:"RATE_LIMIT_CLIENT" default:"600/1m" reload:"true"`
+		Project   string `yaml:"project" env:"RATE_LIMIT_PROJECT" default:"6000/1m" reload:"true"`
+		Routes    string `yaml:"routes" env:"RATE_LIMIT_ROUTES" reload:"true"`
+		Overrides string `yaml:"overrides" env:"RATE_LIMIT_OVERRIDES" reload:"true"`
+	} `yaml:"rateLimit"`
 
 	Postgres struct {
-		User     string
-		Password string
-		Host     string
-		Port     string
-		DBName   string
-		SSLMode  string
-		DSN      string
-	}
+		DSN string `yaml:"dsn" env:"POSTGRES_DSN"`
+	} `yaml:"postgres"`
 
 	Redis struct {
-		Host string
-		Port string
-	}
-}
+		Host     string `yaml:"host" env:"REDIS_HOST" default:"127.0.0.1"`
+		Port     string `yaml:"port" env:"REDIS_PORT" default:"6379"`
+		Password string `yaml:"password" env:"REDIS_PASSWORD"`
+		DB       int    `yaml:"db" env:"REDIS_DB" default:"0"`
+	} `yaml:"redis"`
 
-func LoadConfig() (*Config, error) {
-	cfg := &Config{}
-
-	cfg.once.Do(func() {
-		// Initialize HTTP server configuration
-		cfg.HttpServer.Port = getEnv("HTTP_ADDR", "")
-		cfg.HttpServer.MetricsPort = getEnv("METRICS_PORT", "")
-
-		// Initialize Postgres configuration
-		cfg.Postgres.User = getEnv("POSTGRES_USER", "")
-		cfg.Postgres.Password = getEnv("POSTGRES_PASSWORD", "")
-		cfg.Postgres.Host = getEnv("POSTGRES_HOST", "")
-		cfg.Postgres.Port = getEnv("POSTGRES_PORT", "")
-		cfg.Postgres.DBName = getEnv("POSTGRES_DB", "")
-		cfg.Postgres.SSLMode = getEnv("POSTGRES_SSLMODE", "")
-		cfg.Postgres.DSN = getEnv("POSTGRES_DSN", "")
-
-		// Initialize Redis configuration
-		cfg.Redis.Host = getEnv("REDIS_HOST", "")
-		cfg.Redis.Port = getEnv("REDIS_PORT", "")
-	})
-	return cfg, nil
-}
+	Nats struct {
+		URL      string `yaml:"url" env:"NATS_URL" default:"nats://127.0.0.1:4222"`
+		User     string `yaml:"user" env:"NATS_USER"`
+		Password string `yaml:"password" env:"NATS_PASSWORD"`
+		Token    string `yaml:"token" env:"NATS_TOKEN"`
+		Subjects struct {
+			Events string `yaml:"events" env:"NATS_SUBJECT_EVENTS" default:"events"`
+		} `yaml:"subjects"`
+	} `yaml:"nats"`
+
+	ClickHouse struct {
+		DSN           string        `yaml:"dsn" env:"CLICKHOUSE_DSN" default:"clickhouse://127.0.0.1:9000/default"`
+		MaxOpenConns  int           `yaml:"maxOpenConns" env:"CLICKHOUSE_MAX_OPEN_CONNS" default:"10"`
+		MaxIdleConns  int           `yaml:"maxIdleConns" env:"CLICKHOUSE_MAX_IDLE_CONNS" default:"5"`
+		DialTimeout   time.Duration `yaml:"dialTimeout" env:"CLICKHOUSE_DIAL_TIMEOUT" default:"30s"`
+		BatchSize     int           `yaml:"batchSize" env:"CLICKHOUSE_BATCH_SIZE" default:"100" reload:"true"`
+		FlushInterval time.Duration `yaml:"flushInterval" env:"CLICKHOUSE_FLUSH_INTERVAL" default:"5s" reload:"true"`
+	} `yaml:"clickhouse"`
+
+	Cache struct {
+		GoodsListTTL time.Duration `yaml:"goodsListTTL" env:"CACHE_GOODS_LIST_TTL" default:"1m" reload:"true"`
+	} `yaml:"cache"`
+
+	Features struct {
+		GoodsListCache bool `yaml:"goodsListCache" env:"FEATURE_GOODS_LIST_CACHE" default:"true" reload:"true"`
+		GoodsStream    bool `yaml:"goodsStream" env:"FEATURE_GOODS_STREAM" default:"true" reload:"true"`
+	} `yaml:"features"`
+
+	Logger struct {
+		Level       string `yaml:"level" env:"LOG_LEVEL" default:"info" reload:"true"`
+		Format      string `yaml:"format" env:"LOG_FORMAT" default:"console"`
+		SampleEvery uint32 `yaml:"sampleEvery" env:"LOG_SAMPLE_EVERY" default:"0"`
+	} `yaml:"logger"`
+
+	Timeouts struct {
+		Read   time.Duration `yaml:"read" env:"TIMEOUT_READ" default:"10s"`
+		Write  time.Duration `yaml:"write" env:"TIMEOUT_WRITE" default:"10s"`
+		Search time.Duration `yaml:"search" env:"TIMEOUT_SEARCH" default:"10s"`
+	} `yaml:"timeouts"`
+
+	Webhooks struct {
+		Timeout time.Duration `yaml:"timeout" env:"WEBHOOK_TIMEOUT" default:"10s"`
+	} `yaml:"webhooks"`
+
+	Stream struct {
+		BufferSize int `yaml:"bufferSize" env:"STREAM_BUFFER_SIZE" default:"1000"`
+	} `yaml:"stream"`
+
+	Health struct {
+		CheckTimeout time.Duration `yaml:"checkTimeout" env:"HEALTH_CHECK_TIMEOUT" default:"2s"`
+	} `yaml:"health"`
 
-func getEnv(key string, defaultVal string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
-	}
+	Shutdown struct {
+		Timeout time.Duration `yaml:"timeout" env:"SHUTDOWN_TIMEOUT" default:"30s"`
+	} `yaml:"shutdown"`
 
-	return defaultVal
+	Tracing struct {
+		ServiceName  string  `yaml:"serviceName" env:"TRACING_SERVICE_NAME" default:"rest_clickhouse"`
+		Exporter     string  `yaml:"exporter" env:"TRACING_EXPORTER" default:"none"`
+		FilePath     string  `yaml:"filePath" env:"TRACING_FILE" default:"traces.json"`
+		OTLPEndpoint string  `yaml:"otlpEndpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT" default:"localhost:4317"`
+		SampleRatio  float64 `yaml:"sampleRatio" env:"TRACING_SAMPLE_RATIO" default:"1"`
+	} `yaml:"tracing"`
 }
@@ -0,0 +1,232 @@
+package configs
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	configFileFlag = "config"
+	configFileEnv  = "CONFIG_FILE"
+)
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// LoadConfig собирает конфигурацию из источников по возрастанию приоритета:
+// значения по умолчанию, YAML файл (флаг -config или CONFIG_FILE), переменные окружения, флаги.
+// Ошибки разбора и валидации возвращаются все сразу.
+func LoadConfig(args []string) (*Config, error) {
+	cfg := &Config{}
+
+	if err := walkFields(cfg, func(field reflect.Value, tag reflect.StructTag, path string) error {
+		if value, ok := tag.Lookup("default"); ok {
+			return setField(field, value)
+		}
+		return nil
+	}); err != nil {
+		return nil, fmt.Errorf("invalid config defaults: %w", err)
+	}
+
+	flags, configFile := newFlagSet(cfg)
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if path := resolveConfigFile(*configFile); path != "" {
+		if err := loadFile(cfg, path); err != nil {
+			return nil, err
+		}
+	}
+
+	var errs []error
+	_ = walkFields(cfg, func(field reflect.Value, tag reflect.StructTag, path string) error {
+		key := tag.Get("env")
+		if key == "" {
+			return nil
+		}
+		if value, ok := os.LookupEnv(key); ok {
+			if err := setField(field, value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", key, err))
+			}
+		}
+		return nil
+	})
+
+	values := make(map[string]reflect.Value)
+	_ = walkFields(cfg, func(field reflect.Value, _ reflect.StructTag, path string) error {
+		values[path] = field
+		return nil
+	})
+	flags.Visit(func(f *flag.Flag) {
+		field, ok := values[f.Name]
+		if !ok {
+			return
+		}
+		if err := setField(field, f.Value.String()); err != nil {
+			errs = append(errs, fmt.Errorf("-%s: %w", f.Name, err))
+		}
+	})
+
+	if err := cfg.validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid config:\n%w", errors.Join(errs...))
+	}
+
+	return cfg, nil
+}
+
+// newFlagSet объявляет флаг на каждое поле конфигурации. Значения флагов применяются
+// только для явно переданных флагов, поэтому пустые значения не затирают остальные источники.
+func newFlagSet(cfg *Config) (*flag.FlagSet, *string) {
+	flags := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	configFile := flags.String(configFileFlag, "", "path to YAML config file, env "+configFileEnv)
+
+	_ = walkFields(cfg, func(field reflect.Value, tag reflect.StructTag, path string) error {
+		usage := path
+		if key := tag.Get("env"); key != "" {
+			usage = "env " + key
+		}
+		if field.Kind() == reflect.Bool {
+			flags.Bool(path, field.Bool(), usage)
+			return nil
+		}
+		flags.String(path, formatField(field), usage)
+		return nil
+	})
+
+	return flags, configFile
+}
+
+// configFilePath возвращает путь к YAML файлу, заданный флагом -config или CONFIG_FILE.
+func configFilePath(args []string) (string, error) {
+	flags, configFile := newFlagSet(&Config{})
+	if err := flags.Parse(args); err != nil {
+		return "", err
+	}
+
+	return resolveConfigFile(*configFile), nil
+}
+
+func resolveConfigFile(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+
+	return os.Getenv(configFileEnv)
+}
+
+func loadFile(cfg *Config, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("error reading config file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil {
+		return fmt.Errorf("error parsing config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// walkFields обходит конечные поля конфигурации, path - путь из yaml тегов через точку.
+func walkFields(cfg *Config, fn func(field reflect.Value, tag reflect.StructTag, path string) error) error {
+	return walkStruct(reflect.ValueOf(cfg).Elem(), "", fn)
+}
+
+func walkStruct(v reflect.Value, prefix string, fn func(field reflect.Value, tag reflect.StructTag, path string) error) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		structField := t.Field(i)
+		if !structField.IsExported() {
+			continue
+		}
+
+		name := strings.Split(structField.Tag.Get("yaml"), ",")[0]
+		if name == "" {
+			name = structField.Name
+		}
+		path := name
+		if prefix != "" {
+			path = prefix + "." + name
+		}
+
+		field := v.Field(i)
+		if field.Kind() == reflect.Struct {
+			if err := walkStruct(field, path, fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := fn(field, structField.Tag, path); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+func setField(field reflect.Value, value string) error {
+	if field.Type() == durationType {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsed)
+	case reflect.Int, reflect.Int64:
+		parsed, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(parsed)
+	case reflect.Uint32:
+		parsed, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return err
+		}
+		field.SetUint(parsed)
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(parsed)
+	default:
+		return fmt.Errorf("unsupported config field type %s", field.Type())
+	}
+
+	return nil
+}
+
+func formatField(field reflect.Value) string {
+	if field.Type() == durationType {
+		return time.Duration(field.Int()).String()
+	}
+
+	return fmt.Sprint(field.Interface())
+}
@@ -0,0 +1,94 @@
+package configs
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"rest_clickhouse/pkg/ratelimit"
+	"strconv"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+)
+
+// validate проверяет конфигурацию целиком и возвращает все найденные ошибки одной.
+func (c *Config) validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(isPort(c.HttpServer.Port), "http.port: invalid port %q", c.HttpServer.Port)
+	check(isPort(c.HttpServer.MetricsPort), "http.metricsPort: invalid port %q", c.HttpServer.MetricsPort)
+	check(c.HttpServer.RequestIdHeader != "", "http.requestIdHeader: must not be empty")
+	check(isPort(c.GrpcServer.Port), "grpc.port: invalid port %q", c.GrpcServer.Port)
+
+	if c.Auth.Enabled {
+		check(isOneOf(c.Auth.JWT.Algorithm, "HS256", "RS256"), "auth.jwt.algorithm: must be HS256 or RS256, got %q", c.Auth.JWT.Algorithm)
+		check(c.Auth.JWT.Algorithm != "HS256" || len(c.Auth.JWT.Secret) >= 32, "auth.jwt.secret: at least 32 bytes required for HS256")
+		check(c.Auth.JWT.Algorithm != "RS256" || c.Auth.JWT.PublicKeyFile != "" || c.Auth.JWT.JWKSFile != "", "auth.jwt: publicKeyFile or jwksFile required for RS256")
+		check(c.Auth.ApiKeys.CacheTTL > 0, "auth.apiKeys.cacheTTL: must be positive")
+		check(c.Auth.ApiKeys.LastUsedFlushInterval > 0, "auth.apiKeys.lastUsedFlushInterval: must be positive")
+	}
+
+	_, err := ratelimit.ParseLimit(c.RateLimit.Client)
+	check(err == nil, "rateLimit.client: %v", err)
+	_, err = ratelimit.ParseLimit(c.RateLimit.Project)
+	check(err == nil, "rateLimit.project: %v", err)
+	_, err = ratelimit.ParseRules(c.RateLimit.Routes)
+	check(err == nil, "rateLimit.routes: %v", err)
+	_, err = ratelimit.ParseRules(c.RateLimit.Overrides)
+	check(err == nil, "rateLimit.overrides: %v", err)
+
+	check(c.Postgres.DSN != "", "postgres.dsn: must not be empty")
+
+	check(c.Redis.Host != "", "redis.host: must not be empty")
+	check(isPort(c.Redis.Port), "redis.port: invalid port %q", c.Redis.Port)
+	check(c.Redis.DB >= 0, "redis.db: must not be negative")
+
+	natsURL, err := url.Parse(c.Nats.URL)
+	check(err == nil && natsURL.Host != "", "nats.url: invalid url %q", c.Nats.URL)
+	check(c.Nats.Token == "" || c.Nats.User == "", "nats: token and user credentials are mutually exclusive")
+	check(c.Nats.Subjects.Events != "", "nats.subjects.events: must not be empty")
+
+	_, err = clickhouse.ParseDSN(c.ClickHouse.DSN)
+	check(err == nil, "clickhouse.dsn: %v", err)
+	check(c.ClickHouse.MaxOpenConns > 0, "clickhouse.maxOpenConns: must be positive")
+	check(c.ClickHouse.MaxIdleConns >= 0, "clickhouse.maxIdleConns: must not be negative")
+	check(c.ClickHouse.BatchSize > 0, "clickhouse.batchSize: must be positive")
+	check(c.ClickHouse.FlushInterval > 0, "clickhouse.flushInterval: must be positive")
+
+	check(c.Cache.GoodsListTTL > 0, "cache.goodsListTTL: must be positive")
+
+	check(isOneOf(c.Logger.Level, "trace", "debug", "info", "warn", "error", "fatal", "panic", "disabled"), "logger.level: unknown level %q", c.Logger.Level)
+	check(isOneOf(c.Logger.Format, "console", "json"), "logger.format: must be console or json, got %q", c.Logger.Format)
+
+	check(c.Timeouts.Read >= 0 && c.Timeouts.Write >= 0 && c.Timeouts.Search >= 0, "timeouts: must not be negative")
+	check(c.Webhooks.Timeout > 0, "webhooks.timeout: must be positive")
+	check(c.Stream.BufferSize >= 0, "stream.bufferSize: must not be negative")
+	check(c.Health.CheckTimeout > 0, "health.checkTimeout: must be positive")
+	check(c.Shutdown.Timeout > 0, "shutdown.timeout: must be positive")
+
+	check(isOneOf(c.Tracing.Exporter, "none", "stdout", "file", "otlp"), "tracing.exporter: unknown exporter %q", c.Tracing.Exporter)
+	check(c.Tracing.Exporter != "file" || c.Tracing.FilePath != "", "tracing.filePath: required for file exporter")
+	check(c.Tracing.Exporter != "otlp" || c.Tracing.OTLPEndpoint != "", "tracing.otlpEndpoint: required for otlp exporter")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sampleRatio: must be between 0 and 1")
+
+	return errors.Join(errs...)
+}
+
+func isPort(value string) bool {
+	port, err := strconv.Atoi(value)
+	return err == nil && port > 0 && port <= 65535
+}
+
+func isOneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,187 @@
+package configs
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"rest_clickhouse/pkg/logger"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce объединяет серию событий файловой системы от одного сохранения файла.
+const reloadDebounce = 100 * time.Millisecond
+
+// Watcher перечитывает конфигурацию по SIGHUP и при изменении YAML файла.
+// Применяются только поля с тегом reload; невалидная конфигурация отклоняется целиком,
+// а изменения остальных полей логируются как требующие перезапуска.
+type Watcher struct {
+	args     []string
+	logger   logger.Logger
+	mu       sync.Mutex
+	current  *Config
+	appliers []func(cfg *Config)
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func NewWatcher(current *Config, args []string, logger logger.Logger) *Watcher {
+	return &Watcher{
+		args:    args,
+		logger:  logger,
+		current: current,
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// OnReload регистрирует функцию, которая применяет новые значения к работающим компонентам.
+// Регистрировать нужно до Start.
+func (w *Watcher) OnReload(apply func(cfg *Config)) {
+	w.appliers = append(w.appliers, apply)
+}
+
+func (w *Watcher) Start() error {
+	path, err := configFilePath(w.args)
+	if err != nil {
+		return err
+	}
+
+	var fsWatcher *fsnotify.Watcher
+	if path != "" {
+		fsWatcher, err = fsnotify.NewWatcher()
+		if err != nil {
+			return fmt.Errorf("error creating config file watcher: %w", err)
+		}
+		// Следим за каталогом: редакторы и ConfigMap заменяют файл, и наблюдение за ним самим теряется.
+		if err := fsWatcher.Add(filepath.Dir(path)); err != nil {
+			fsWatcher.Close()
+			return fmt.Errorf("error watching config file %s: %w", path, err)
+		}
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	go w.watch(path, fsWatcher, signals)
+	w.logger.Info("Config watcher started!")
+
+	return nil
+}
+
+func (w *Watcher) watch(path string, fsWatcher *fsnotify.Watcher, signals chan os.Signal) {
+	defer close(w.done)
+	defer signal.Stop(signals)
+
+	var (
+		events   <-chan fsnotify.Event
+		errs     <-chan error
+		debounce <-chan time.Time
+	)
+	if fsWatcher != nil {
+		defer fsWatcher.Close()
+		events, errs = fsWatcher.Events, fsWatcher.Errors
+	}
+
+	for {
+		select {
+		case <-signals:
+			w.logger.Info("SIGHUP received, reloading config")
+			_ = w.Reload()
+		case event, ok := <-events:
+			if !ok {
+				events = nil
+				continue
+			}
+			if filepath.Clean(event.Name) != filepath.Clean(path) || !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+				continue
+			}
+			debounce = time.After(reloadDebounce)
+		case <-debounce:
+			debounce = nil
+			w.logger.InfoF("config file %s changed, reloading config", path)
+			_ = w.Reload()
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			w.logger.ErrorF("config watcher error: %v", err)
+		case <-w.stop:
+			return
+		}
+	}
+}
+
+// Reload собирает конфигурацию заново из всех источников и применяет изменившиеся поля с тегом reload.
+func (w *Watcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	next, err := LoadConfig(w.args)
+	if err != nil {
+		w.logger.ErrorF("config reload rejected, keeping current config: %v", err)
+		return err
+	}
+
+	applied := new(Config)
+	*applied = *w.current
+
+	currentFields := fieldsByPath(w.current)
+	appliedFields := fieldsByPath(applied)
+	changed := false
+	_ = walkFields(next, func(field reflect.Value, tag reflect.StructTag, path string) error {
+		old := currentFields[path]
+		if reflect.DeepEqual(old.Interface(), field.Interface()) {
+			return nil
+		}
+		if tag.Get("reload") != "true" {
+			// Значения не логируем: среди таких полей есть пароли и токены.
+			w.logger.WarnF("config reload: %s changed, restart required to apply", path)
+			return nil
+		}
+
+		w.logger.InfoF("config reload: %s: %s -> %s", path, formatField(old), formatField(field))
+		appliedFields[path].Set(field)
+		changed = true
+		return nil
+	})
+	if !changed {
+		w.logger.Info("config reload: nothing to apply")
+		return nil
+	}
+
+	for _, apply := range w.appliers {
+		apply(applied)
+	}
+	w.current = applied
+
+	return nil
+}
+
+func (w *Watcher) Stop(ctx context.Context) error {
+	close(w.stop)
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func fieldsByPath(cfg *Config) map[string]reflect.Value {
+	fields := make(map[string]reflect.Value)
+	_ = walkFields(cfg, func(field reflect.Value, _ reflect.StructTag, path string) error {
+		fields[path] = field
+		return nil
+	})
+
+	return fields
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	"time"
+)
+
+type ApiKey struct {
+	Id         int        `json:"id,omitempty"`
+	ProjectId  int        `json:"projectId,omitempty"`
+	Name       string     `json:"name"`
+	Role       string     `json:"role"`
+	Prefix     string     `json:"prefix,omitempty"`
+	Key        string     `json:"key,omitempty"`
+	CreatedAt  *time.Time `json:"createdAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+type ApiKeyList struct {
+	ApiKeys []ApiKey `json:"apiKeys"`
+}
+
+// GetCreatedApiKey возвращает ключ целиком, он показывается только при создании и ротации.
+func GetCreatedApiKey(ApiKeyModel *repository.ApiKeyModel) ApiKey {
+	apiKey := GetApiKey(ApiKeyModel)
+	apiKey.Key = ApiKeyModel.Key
+
+	return apiKey
+}
+
+func GetApiKey(ApiKeyModel *repository.ApiKeyModel) ApiKey {
+	return ApiKey{
+		Id:         ApiKeyModel.Id,
+		ProjectId:  ApiKeyModel.ProjectId,
+		Name:       ApiKeyModel.Name,
+		Role:       ApiKeyModel.Role,
+		Prefix:     ApiKeyModel.Prefix,
+		CreatedAt:  &ApiKeyModel.CreatedAt,
+		LastUsedAt: ApiKeyModel.LastUsedAt,
+		RevokedAt:  ApiKeyModel.RevokedAt,
+	}
+}
+
+func GetApiKeyList(ApiKeyModels []*repository.ApiKeyModel) ApiKeyList {
+	apiKeyList := ApiKeyList{ApiKeys: make([]ApiKey, len(ApiKeyModels))}
+	for i, ApiKeyModel := range ApiKeyModels {
+		apiKeyList.ApiKeys[i] = GetApiKey(ApiKeyModel)
+	}
+
+	return apiKeyList
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	"time"
+)
+
+type Attribute struct {
+	Id         int        `json:"id,omitempty"`
+	ProjectId  int        `json:"projectId,omitempty"`
+	Name       string     `json:"name"`
+	Type       string     `json:"type"`
+	Required   bool       `json:"required"`
+	EnumValues []string   `json:"enumValues,omitempty"`
+	CreatedAt  *time.Time `json:"createdAt,omitempty"`
+}
+
+type AttributeList struct {
+	Attributes []Attribute `json:"attributes"`
+}
+
+func GetAttribute(AttributeModel *repository.AttributeModel) Attribute {
+	return Attribute{
+		Id:         AttributeModel.Id,
+		ProjectId:  AttributeModel.ProjectId,
+		Name:       AttributeModel.Name,
+		Type:       AttributeModel.Type,
+		Required:   AttributeModel.Required,
+		EnumValues: AttributeModel.EnumValues,
+		CreatedAt:  &AttributeModel.CreatedAt,
+	}
+}
+
+func GetAttributeList(AttributeModels []*repository.AttributeModel) AttributeList {
+	attributeList := AttributeList{Attributes: make([]Attribute, len(AttributeModels))}
+	for i, AttributeModel := range AttributeModels {
+		attributeList.Attributes[i] = GetAttribute(AttributeModel)
+	}
+
+	return attributeList
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	"time"
+)
+
+type AuditRecord struct {
+	GoodId     int       `json:"goodId"`
+	ProjectId  int       `json:"projectId"`
+	Name       string    `json:"name"`
+	EventType  string    `json:"eventType"`
+	Removed    bool      `json:"removed"`
+	Actor      string    `json:"actor"`
+	AuthMethod string    `json:"authMethod"`
+	ClientIP   string    `json:"clientIp"`
+	UserAgent  string    `json:"userAgent"`
+	RequestId  string    `json:"requestId"`
+	EventTime  time.Time `json:"eventTime"`
+}
+
+type AuditList struct {
+	Records []AuditRecord `json:"records"`
+}
+
+func GetAuditList(AuditRecordModels []*repository.AuditRecordModel) AuditList {
+	auditList := AuditList{Records: make([]AuditRecord, len(AuditRecordModels))}
+	for i, record := range AuditRecordModels {
+		auditList.Records[i] = AuditRecord{
+			GoodId:     record.Id,
+			ProjectId:  record.ProjectId,
+			Name:       record.Name,
+			EventType:  record.EventType,
+			Removed:    record.Removed,
+			Actor:      record.Actor,
+			AuthMethod: record.AuthMethod,
+			ClientIP:   record.ClientIP,
+			UserAgent:  record.UserAgent,
+			RequestId:  record.RequestId,
+			EventTime:  record.EventTime,
+		}
+	}
+
+	return auditList
+}
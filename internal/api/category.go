@@ -0,0 +1,81 @@
+package api
+
+import (
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	"time"
+)
+
+type Category struct {
+	Id        int        `json:"id,omitempty"`
+	ProjectId int        `json:"projectId,omitempty"`
+	ParentId  *int       `json:"parentId,omitempty"`
+	Name      string     `json:"name,omitempty" validate:"trim,required,max=256,chars=printable"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+
+	Children []*Category `json:"children,omitempty"`
+}
+
+type CategoryList struct {
+	Categories []*Category `json:"categories"`
+}
+
+type Tag struct {
+	Name       string `json:"name"`
+	GoodsCount int    `json:"goodsCount"`
+}
+
+type TagList struct {
+	Tags []Tag `json:"tags"`
+}
+
+type GoodTags struct {
+	Tags []string `json:"tags" validate:"max=50,dive,trim,max=64,chars=printable"`
+}
+
+type GoodCategory struct {
+	CategoryId *int `json:"categoryId" validate:"min=1"`
+}
+
+func GetCategory(CategoryModel *repository.CategoryModel) *Category {
+	return &Category{
+		Id:        CategoryModel.Id,
+		ProjectId: CategoryModel.ProjectId,
+		ParentId:  CategoryModel.ParentId,
+		Name:      CategoryModel.Name,
+		CreatedAt: &CategoryModel.CreatedAt,
+	}
+}
+
+// GetCategoryTree собирает плоский список категорий проекта в дерево.
+func GetCategoryTree(CategoryModels []*repository.CategoryModel) CategoryList {
+	byId := make(map[int]*Category, len(CategoryModels))
+	for _, CategoryModel := range CategoryModels {
+		byId[CategoryModel.Id] = GetCategory(CategoryModel)
+	}
+
+	categoryList := CategoryList{Categories: make([]*Category, 0)}
+	for _, CategoryModel := range CategoryModels {
+		category := byId[CategoryModel.Id]
+		if CategoryModel.ParentId != nil {
+			if parent, ok := byId[*CategoryModel.ParentId]; ok {
+				parent.Children = append(parent.Children, category)
+				continue
+			}
+		}
+		categoryList.Categories = append(categoryList.Categories, category)
+	}
+
+	return categoryList
+}
+
+func GetTagList(TagModels []*repository.TagModel) TagList {
+	tagList := TagList{Tags: make([]Tag, len(TagModels))}
+	for i, TagModel := range TagModels {
+		tagList.Tags[i] = Tag{
+			Name:       TagModel.Name,
+			GoodsCount: TagModel.GoodsCount,
+		}
+	}
+
+	return tagList
+}
@@ -1,14 +1,12 @@
 package api
 
+// ErrorResponse - тело любого ответа с ошибкой. Коды и ключи сообщений перечислены в пакете apperrors.
 type ErrorResponse struct {
 	Code    int         `json:"code"`
 	Message string      `json:"message"`
 	Details interface{} `json:"details"`
 }
 
-const GoodNotFoundMessage = "errors.good.notFound"
-const GoodNotFoundCode = 3
-
 func NewErrorResponse(code int, message string, details ...interface{}) ErrorResponse {
 	return ErrorResponse{
 		Code:    code,
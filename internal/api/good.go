@@ -1,18 +1,87 @@
 package api
 
 import (
+	"encoding/json"
+	"errors"
 	"rest_clickhouse/internal/infrastructure/usecase/repository"
 	"time"
 )
 
+// Good - товар в запросах и ответах. Отсутствующий при создании приоритет заменяется значением по умолчанию.
 type Good struct {
-	Id          int        `json:"id,omitempty"`
-	ProjectId   int        `json:"projectId,omitempty"`
-	Name        string     `json:"name,omitempty"`
-	Description string     `json:"description,omitempty"`
-	Priority    int        `json:"priority,omitempty"`
-	Removed     bool       `json:"removed,omitempty"`
-	CreatedAt   *time.Time `json:"createdAt,omitempty"`
+	Id          int                    `json:"id,omitempty"`
+	ProjectId   int                    `json:"projectId,omitempty"`
+	Name        string                 `json:"name,omitempty" validate:"trim,required,max=256,chars=printable"`
+	Description string                 `json:"description,omitempty" validate:"max=10000,chars=text"`
+	Priority    *int                   `json:"priority,omitempty" validate:"min=0,max=2147483647"`
+	Removed     bool                   `json:"removed,omitempty"`
+	CreatedAt   *time.Time             `json:"createdAt,omitempty"`
+	CategoryId  *int                   `json:"categoryId,omitempty"`
+	Tags        []string               `json:"tags,omitempty" validate:"max=50,dive,trim,max=64,chars=printable"`
+	Attributes  map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// GoodChange - товар в событии потока изменений. В отличие от Good все поля, кроме категории, передаются всегда,
+// тип изменения передается в поле event события.
+type GoodChange struct {
+	Id          int                    `json:"id"`
+	ProjectId   int                    `json:"projectId"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	Priority    int                    `json:"priority"`
+	Removed     bool                   `json:"removed"`
+	CreatedAt   time.Time              `json:"createdAt"`
+	CategoryId  *int                   `json:"categoryId,omitempty"`
+	Tags        []string               `json:"tags"`
+	Attributes  map[string]interface{} `json:"attributes"`
+}
+
+// GoodPatch - изменение товара в формате JSON Merge Patch (RFC 7396): отсутствующее поле не меняется,
+// null сбрасывает поле к значению по умолчанию. Атрибуты сливаются по ключам, null удаляет атрибут.
+type GoodPatch struct {
+	Id          int                    `json:"-"`
+	ProjectId   int                    `json:"-"`
+	Name        *string                `json:"name,omitempty" validate:"trim,nonempty,max=256,chars=printable"`
+	Description *string                `json:"description,omitempty" validate:"max=10000,chars=text"`
+	Priority    *int                   `json:"priority,omitempty" validate:"min=0,max=2147483647"`
+	Attributes  map[string]interface{} `json:"attributes,omitempty"`
+
+	fields map[string]bool
+}
+
+func (p *GoodPatch) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if fields == nil {
+		return errors.New("merge patch must be a JSON object")
+	}
+
+	type goodPatch GoodPatch
+	if err := json.Unmarshal(data, (*goodPatch)(p)); err != nil {
+		return err
+	}
+
+	p.fields = make(map[string]bool, len(fields))
+	for name := range fields {
+		p.fields[name] = true
+	}
+
+	return nil
+}
+
+// Has сообщает, передано ли поле в теле запроса, в том числе со значением null.
+func (p *GoodPatch) Has(field string) bool {
+	return p.fields[field]
+}
+
+// GoodListFilter содержит фильтры списка товаров из query-параметров.
+type GoodListFilter struct {
+	ProjectId  int               `json:"projectId,omitempty"`
+	Tag        string            `json:"tag,omitempty"`
+	CategoryId int               `json:"categoryId,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
 }
 
 type GoodList struct {
@@ -21,6 +90,24 @@ type GoodList struct {
 	Goods []Good `json:"goods"`
 }
 
+type GoodSearchResult struct {
+	Good
+	Rank      float64   `json:"rank"`
+	Highlight Highlight `json:"highlight"`
+}
+
+// Highlight содержит экранированный HTML: текст товара экранирован, совпадения обернуты в <b>.
+type Highlight struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type GoodSearchList struct {
+	Meta Meta `json:"meta"`
+
+	Goods []GoodSearchResult `json:"goods"`
+}
+
 type Meta struct {
 	Total   int `json:"total"`
 	Removed int `json:"removed"`
@@ -45,9 +132,12 @@ func GetGoodList(GoodModels repository.GoodModelList) GoodList {
 			ProjectId:   GoodModel.ProjectId,
 			Name:        GoodModel.Name,
 			Description: GoodModel.Description,
-			Priority:    GoodModel.Priority,
+			Priority:    &GoodModel.Priority,
 			Removed:     GoodModel.Removed,
 			CreatedAt:   &GoodModel.CreatedAt,
+			CategoryId:  GoodModel.CategoryId,
+			Tags:        GoodModel.Tags,
+			Attributes:  GoodModel.Attributes,
 		}
 		goodList.Goods[i] = good
 	}
@@ -55,6 +145,37 @@ func GetGoodList(GoodModels repository.GoodModelList) GoodList {
 	return goodList
 }
 
+func GetGoodSearchList(GoodModels repository.GoodSearchModelList) GoodSearchList {
+	searchList := GoodSearchList{
+		Meta: Meta{
+			Total:  GoodModels.Meta.Total,
+			Limit:  GoodModels.Meta.Limit,
+			Offset: GoodModels.Meta.Offset,
+		},
+		Goods: make([]GoodSearchResult, len(GoodModels.Goods)),
+	}
+
+	for i, GoodModel := range GoodModels.Goods {
+		searchList.Goods[i] = GoodSearchResult{
+			Good: Good{
+				Id:          GoodModel.Id,
+				ProjectId:   GoodModel.ProjectId,
+				Name:        GoodModel.Name,
+				Description: GoodModel.Description,
+				Priority:    &GoodModel.Priority,
+				CreatedAt:   &GoodModel.CreatedAt,
+			},
+			Rank: GoodModel.Rank,
+			Highlight: Highlight{
+				Name:        GoodModel.NameHighlight,
+				Description: GoodModel.DescriptionHighlight,
+			},
+		}
+	}
+
+	return searchList
+}
+
 func GetRemovedGood(GoodModel *repository.GoodModel) Good {
 	return Good{
 		Id:        GoodModel.Id,
@@ -63,13 +184,55 @@ func GetRemovedGood(GoodModel *repository.GoodModel) Good {
 	}
 }
 
+func GetGood(GoodModel *repository.GoodModel) Good {
+	return Good{
+		Id:          GoodModel.Id,
+		ProjectId:   GoodModel.ProjectId,
+		Name:        GoodModel.Name,
+		Description: GoodModel.Description,
+		Priority:    &GoodModel.Priority,
+		Removed:     GoodModel.Removed,
+		CreatedAt:   &GoodModel.CreatedAt,
+		CategoryId:  GoodModel.CategoryId,
+		Tags:        GoodModel.Tags,
+		Attributes:  GoodModel.Attributes,
+	}
+}
+
 func GetUpdatedGood(GoodModel *repository.GoodModel) Good {
 	return Good{
-		Id:        GoodModel.Id,
-		ProjectId: GoodModel.ProjectId,
-		Name:      GoodModel.Name,
-		Priority:  GoodModel.Priority,
-		Removed:   GoodModel.Removed,
-		CreatedAt: &GoodModel.CreatedAt,
+		Id:          GoodModel.Id,
+		ProjectId:   GoodModel.ProjectId,
+		Name:        GoodModel.Name,
+		Description: GoodModel.Description,
+		Priority:    &GoodModel.Priority,
+		Removed:     GoodModel.Removed,
+		CreatedAt:   &GoodModel.CreatedAt,
+		CategoryId:  GoodModel.CategoryId,
+		Tags:        GoodModel.Tags,
+		Attributes:  GoodModel.Attributes,
 	}
 }
+
+func GetGoodChange(GoodModel *repository.GoodModel) GoodChange {
+	change := GoodChange{
+		Id:          GoodModel.Id,
+		ProjectId:   GoodModel.ProjectId,
+		Name:        GoodModel.Name,
+		Description: GoodModel.Description,
+		Priority:    GoodModel.Priority,
+		Removed:     GoodModel.Removed,
+		CreatedAt:   GoodModel.CreatedAt,
+		CategoryId:  GoodModel.CategoryId,
+		Tags:        GoodModel.Tags,
+		Attributes:  GoodModel.Attributes,
+	}
+	if change.Tags == nil {
+		change.Tags = []string{}
+	}
+	if change.Attributes == nil {
+		change.Attributes = map[string]interface{}{}
+	}
+
+	return change
+}
@@ -0,0 +1,33 @@
+package api
+
+import "encoding/json"
+
+type GraphQLRequest struct {
+	Query         string                 `json:"query" validate:"required"`
+	OperationName string                 `json:"operationName,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+}
+
+// GraphQLResponse - ответ на запрос GraphQL: данные и ошибки исполнения.
+type GraphQLResponse struct {
+	Data   json.RawMessage `json:"data,omitempty"`
+	Errors []GraphQLError  `json:"errors,omitempty"`
+}
+
+// GraphQLError - ошибка GraphQL. Ошибки резолверов несут в extensions код и детали, как ErrorResponse.
+type GraphQLError struct {
+	Message    string                  `json:"message"`
+	Locations  []GraphQLLocation       `json:"locations,omitempty"`
+	Path       []interface{}           `json:"path,omitempty"`
+	Extensions *GraphQLErrorExtensions `json:"extensions,omitempty"`
+}
+
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+type GraphQLErrorExtensions struct {
+	Code    int           `json:"code"`
+	Details []interface{} `json:"details,omitempty"`
+}
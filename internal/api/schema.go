@@ -0,0 +1,164 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema - JSON Schema объекта в спецификации OpenAPI.
+type Schema map[string]interface{}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// Schemas строит схемы OpenAPI для DTO по их Go типам: поля и имена берутся из json тегов, поле без omitempty
+// или с правилом required обязательно, ограничения min и max из тегов validate переносятся в схему.
+// Типы из dtos, встреченные внутри других, подставляются ссылкой #/components/schemas/<имя>.
+func Schemas(dtos map[string]interface{}) map[string]Schema {
+	names := make(map[reflect.Type]string, len(dtos))
+	for name, dto := range dtos {
+		names[reflect.TypeOf(dto)] = name
+	}
+
+	schemas := make(map[string]Schema, len(dtos))
+	for name, dto := range dtos {
+		schemas[name] = objectSchema(reflect.TypeOf(dto), names)
+	}
+
+	return schemas
+}
+
+func typeSchema(t reflect.Type, names map[reflect.Type]string) Schema {
+	if name, ok := names[t]; ok {
+		return Schema{"$ref": "#/components/schemas/" + name}
+	}
+
+	switch t {
+	case timeType:
+		return Schema{"type": "string", "format": "date-time"}
+	case rawMessageType:
+		return Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := typeSchema(t.Elem(), names)
+		if _, ok := schema["$ref"]; !ok {
+			schema["nullable"] = true
+		}
+		return schema
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return Schema{"type": "integer"}
+	case reflect.Int64, reflect.Uint64:
+		return Schema{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return Schema{"type": "array", "items": typeSchema(t.Elem(), names)}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": typeSchema(t.Elem(), names)}
+	case reflect.Struct:
+		return objectSchema(t, names)
+	default:
+		return Schema{}
+	}
+}
+
+// objectSchema описывает структуру, поля встроенных структур поднимаются на ее уровень, как в encoding/json.
+func objectSchema(t reflect.Type, names map[reflect.Type]string) Schema {
+	properties := Schema{}
+	var required []string
+
+	var collect func(t reflect.Type)
+	collect = func(t reflect.Type) {
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				collect(field.Type)
+				continue
+			}
+			if !field.IsExported() || field.Tag.Get("json") == "-" {
+				continue
+			}
+
+			name := jsonName(field)
+			schema := typeSchema(field.Type, names)
+			rules := strings.Split(field.Tag.Get("validate"), ",")
+			applyRules(schema, rules)
+			properties[name] = schema
+
+			_, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+			if !strings.Contains(options, "omitempty") || hasRule(rules, "required") {
+				required = append(required, name)
+			}
+		}
+	}
+	collect(t)
+
+	schema := Schema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+// applyRules переносит ограничения правил validate в схему, правила после dive - в схему элементов.
+func applyRules(schema Schema, rules []string) {
+	for i, rule := range rules {
+		if rule == "dive" {
+			if items, ok := schema["items"].(Schema); ok {
+				applyRules(items, rules[i+1:])
+			}
+			return
+		}
+
+		name, arg, _ := strings.Cut(rule, "=")
+		// Для строк и массивов непустое значение - длина не меньше 1.
+		if name == "nonempty" && (schema["type"] == "string" || schema["type"] == "array") {
+			name, arg = "min", "1"
+		}
+		if name != "min" && name != "max" {
+			continue
+		}
+		limit, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		var keyword string
+		switch schema["type"] {
+		case "string":
+			keyword = name + "Length"
+		case "array":
+			keyword = name + "Items"
+		case "integer", "number":
+			keyword = map[string]string{"min": "minimum", "max": "maximum"}[name]
+		default:
+			continue
+		}
+		schema[keyword] = limit
+	}
+}
+
+func hasRule(rules []string, rule string) bool {
+	for _, r := range rules {
+		if r == "dive" {
+			return false
+		}
+		if r == rule {
+			return true
+		}
+	}
+
+	return false
+}
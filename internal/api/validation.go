@@ -0,0 +1,160 @@
+package api
+
+import (
+	"fmt"
+	"reflect"
+	"rest_clickhouse/internal/apperrors"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// charsets - наборы допустимых символов для правила chars.
+var charsets = map[string]func(r rune) bool{
+	// printable - без управляющих символов и переводов строк.
+	"printable": unicode.IsPrint,
+	// text - печатные символы и переводы строк.
+	"text": func(r rune) bool {
+		return unicode.IsPrint(r) || r == '\n' || r == '\r' || r == '\t'
+	},
+}
+
+// Validate проверяет поля DTO по тегам validate и возвращает все нарушения одной ошибкой apperrors.InvalidRequest.
+// dto - указатель на структуру, поле называется в ошибке по json тегу. Правила через запятую выполняются по порядку,
+// на поле сообщается первое нарушение:
+//   - trim - убирает пробелы по краям строки, меняя поле;
+//   - required - значение не пустое, указатель не nil;
+//   - nonempty - значение не пустое, если передано: указатель nil допустим;
+//   - min=N, max=N - длина строки в символах, число элементов среза или значение числа;
+//   - chars=name - все символы строки из набора charsets;
+//   - dive - следующие правила применяются к каждому элементу среза.
+//
+// Для указателя, равного nil, проверяется только required.
+func Validate(dto interface{}) error {
+	value := reflect.ValueOf(dto)
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("api.Validate: expected pointer to struct, got %T", dto))
+	}
+	value = value.Elem()
+
+	err := apperrors.InvalidRequest
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		tag, ok := field.Tag.Lookup("validate")
+		if !ok || !field.IsExported() {
+			continue
+		}
+
+		name := jsonName(field)
+		if message := checkRules(value.Field(i), strings.Split(tag, ","), name, &err); message != "" {
+			err = err.WithField(name, message)
+		}
+	}
+	if len(err.Details) > 0 {
+		return err
+	}
+
+	return nil
+}
+
+// checkRules применяет правила к значению и возвращает первое нарушение.
+// Нарушения элементов среза после dive добавляются в err сразу, с индексом в имени поля.
+func checkRules(value reflect.Value, rules []string, name string, err **apperrors.Error) string {
+	for i, rule := range rules {
+		if rule == "dive" {
+			for j := 0; j < value.Len(); j++ {
+				element := fmt.Sprintf("%s[%d]", name, j)
+				if message := checkRules(value.Index(j), rules[i+1:], element, err); message != "" {
+					*err = (*err).WithField(element, message)
+				}
+			}
+			return ""
+		}
+
+		if value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				if rule == "required" {
+					return "required"
+				}
+				continue
+			}
+			value = value.Elem()
+		}
+
+		if message := checkRule(value, rule); message != "" {
+			return message
+		}
+	}
+
+	return ""
+}
+
+func checkRule(value reflect.Value, rule string) string {
+	name, arg, _ := strings.Cut(rule, "=")
+	switch name {
+	case "trim":
+		if value.Kind() == reflect.String && value.CanSet() {
+			value.SetString(strings.TrimSpace(value.String()))
+		}
+	case "required":
+		if value.IsZero() || (value.Kind() == reflect.Slice || value.Kind() == reflect.Map) && value.Len() == 0 {
+			return "required"
+		}
+	case "nonempty":
+		if value.IsZero() || (value.Kind() == reflect.Slice || value.Kind() == reflect.Map) && value.Len() == 0 {
+			return "must not be empty"
+		}
+	case "min", "max":
+		limit, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			panic(fmt.Sprintf("api.Validate: invalid rule %q", rule))
+		}
+		return checkLimit(value, name, limit)
+	case "chars":
+		allowed, ok := charsets[arg]
+		if !ok {
+			panic(fmt.Sprintf("api.Validate: unknown charset %q", arg))
+		}
+		if strings.IndexFunc(value.String(), func(r rune) bool { return !allowed(r) }) >= 0 {
+			return "contains invalid characters"
+		}
+	default:
+		panic(fmt.Sprintf("api.Validate: unknown rule %q", rule))
+	}
+
+	return ""
+}
+
+func checkLimit(value reflect.Value, bound string, limit int64) string {
+	var size int64
+	var unit string
+	switch value.Kind() {
+	case reflect.String:
+		size, unit = int64(utf8.RuneCountInString(value.String())), " characters"
+	case reflect.Slice, reflect.Map:
+		size, unit = int64(value.Len()), " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		size = value.Int()
+	default:
+		panic(fmt.Sprintf("api.Validate: %s is not applicable to %s", bound, value.Kind()))
+	}
+
+	if bound == "min" && size < limit {
+		return fmt.Sprintf("must be at least %d%s", limit, unit)
+	}
+	if bound == "max" && size > limit {
+		return fmt.Sprintf("must be at most %d%s", limit, unit)
+	}
+
+	return ""
+}
+
+func jsonName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+
+	return name
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	"time"
+)
+
+type Webhook struct {
+	Id         int        `json:"id,omitempty"`
+	ProjectId  int        `json:"projectId,omitempty"`
+	Url        string     `json:"url"`
+	Secret     string     `json:"secret,omitempty"`
+	EventTypes []string   `json:"eventTypes"`
+	Active     bool       `json:"active"`
+	CreatedAt  *time.Time `json:"createdAt,omitempty"`
+}
+
+type WebhookList struct {
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+type WebhookDelivery struct {
+	Id           int64           `json:"id"`
+	WebhookId    int             `json:"webhookId"`
+	EventType    string          `json:"eventType"`
+	Payload      json.RawMessage `json:"payload"`
+	Status       string          `json:"status"`
+	Attempts     int             `json:"attempts"`
+	ResponseCode *int            `json:"responseCode,omitempty"`
+	LastError    string          `json:"lastError,omitempty"`
+	CreatedAt    time.Time       `json:"createdAt"`
+	DeliveredAt  *time.Time      `json:"deliveredAt,omitempty"`
+}
+
+type WebhookDeliveryList struct {
+	Deliveries []WebhookDelivery `json:"deliveries"`
+}
+
+// GetCreatedWebhook возвращает подписку вместе с секретом, он показывается только при создании.
+func GetCreatedWebhook(WebhookModel *repository.WebhookModel) Webhook {
+	webhook := GetWebhook(WebhookModel)
+	webhook.Secret = WebhookModel.Secret
+
+	return webhook
+}
+
+func GetWebhook(WebhookModel *repository.WebhookModel) Webhook {
+	return Webhook{
+		Id:         WebhookModel.Id,
+		ProjectId:  WebhookModel.ProjectId,
+		Url:        WebhookModel.Url,
+		EventTypes: WebhookModel.EventTypes,
+		Active:     WebhookModel.Active,
+		CreatedAt:  &WebhookModel.CreatedAt,
+	}
+}
+
+func GetWebhookList(WebhookModels []*repository.WebhookModel) WebhookList {
+	webhookList := WebhookList{Webhooks: make([]Webhook, len(WebhookModels))}
+	for i, WebhookModel := range WebhookModels {
+		webhookList.Webhooks[i] = GetWebhook(WebhookModel)
+	}
+
+	return webhookList
+}
+
+func GetWebhookDelivery(DeliveryModel *repository.WebhookDeliveryModel) WebhookDelivery {
+	return WebhookDelivery{
+		Id:           DeliveryModel.Id,
+		WebhookId:    DeliveryModel.WebhookId,
+		EventType:    DeliveryModel.EventType,
+		Payload:      DeliveryModel.Payload,
+		Status:       DeliveryModel.Status,
+		Attempts:     DeliveryModel.Attempts,
+		ResponseCode: DeliveryModel.ResponseCode,
+		LastError:    DeliveryModel.LastError,
+		CreatedAt:    DeliveryModel.CreatedAt,
+		DeliveredAt:  DeliveryModel.DeliveredAt,
+	}
+}
+
+func GetWebhookDeliveryList(DeliveryModels []*repository.WebhookDeliveryModel) WebhookDeliveryList {
+	deliveryList := WebhookDeliveryList{Deliveries: make([]WebhookDelivery, len(DeliveryModels))}
+	for i, DeliveryModel := range DeliveryModels {
+		deliveryList.Deliveries[i] = GetWebhookDelivery(DeliveryModel)
+	}
+
+	return deliveryList
+}
@@ -0,0 +1,27 @@
+package apperrors
+
+// Коды и ключи сообщений - часть API: существующие не меняются, новые добавляются в конец.
+var (
+	GoodNotFound            = New(KindNotFound, 3, "errors.good.notFound", "good not exist")
+	CategoryNotFound        = New(KindNotFound, 4, "errors.category.notFound", "category not exist")
+	InvalidAttributes       = New(KindValidation, 5, "errors.good.invalidAttributes", "invalid attributes")
+	AttributeNotFound       = New(KindNotFound, 6, "errors.attribute.notFound", "attribute not exist")
+	AttributeAlreadyExist   = New(KindConflict, 7, "errors.attribute.alreadyExist", "attribute already exist")
+	InvalidAttributeSchema  = New(KindValidation, 8, "errors.attribute.invalidSchema", "invalid attribute schema")
+	WebhookNotFound         = New(KindNotFound, 9, "errors.webhook.notFound", "webhook not exist")
+	WebhookDeliveryNotFound = New(KindNotFound, 10, "errors.webhook.deliveryNotFound", "webhook delivery not exist")
+	InvalidWebhook          = New(KindValidation, 11, "errors.webhook.invalid", "invalid webhook")
+	Internal                = New(KindInternal, 12, "errors.internal", "internal error")
+	Unauthorized            = New(KindUnauthorized, 13, "errors.auth.unauthorized", "unauthorized")
+	Forbidden               = New(KindForbidden, 14, "errors.auth.forbidden", "forbidden")
+	ApiKeyNotFound          = New(KindNotFound, 15, "errors.apiKey.notFound", "api key not exist")
+	InvalidApiKey           = New(KindValidation, 16, "errors.apiKey.invalid", "invalid api key request")
+	TooManyRequests         = New(KindTooManyRequests, 17, "errors.rateLimit.exceeded", "rate limit exceeded")
+	InvalidAuditFilter      = New(KindValidation, 18, "errors.audit.invalidFilter", "invalid audit filter")
+	ProjectNotFound         = New(KindNotFound, 19, "errors.project.notFound", "project not exist")
+	InvalidRequest          = New(KindValidation, 20, "errors.request.invalid", "invalid request")
+	RouteNotFound           = New(KindNotFound, 21, "errors.route.notFound", "route not found")
+	FeatureDisabled         = New(KindUnavailable, 22, "errors.feature.disabled", "feature is disabled")
+	NotAcceptable           = New(KindNotAcceptable, 23, "errors.request.notAcceptable", "not acceptable")
+	UnsupportedMediaType    = New(KindUnsupportedMediaType, 24, "errors.request.unsupportedMediaType", "unsupported media type")
+)
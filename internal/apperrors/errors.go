@@ -0,0 +1,84 @@
+package apperrors
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Kind - вид ошибки, по нему транспорт выбирает статус ответа.
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindNotFound
+	KindConflict
+	KindValidation
+	KindUnavailable
+	KindUnauthorized
+	KindForbidden
+	KindTooManyRequests
+	KindNotAcceptable
+	KindUnsupportedMediaType
+)
+
+// Error - ошибка предметной области. Code и Message - стабильные код и ключ сообщения для клиентов,
+// Details уточняют ошибку, например нарушения по полям запроса.
+// Ошибки сравниваются по коду, поэтому errors.Is находит ошибку каталога и после WithDetails.
+type Error struct {
+	Kind    Kind
+	Code    int
+	Message string
+	Details []interface{}
+	text    string
+}
+
+// FieldError - нарушение в одном поле запроса.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func New(kind Kind, code int, message, text string) *Error {
+	return &Error{
+		Kind:    kind,
+		Code:    code,
+		Message: message,
+		text:    text,
+	}
+}
+
+func (e *Error) Error() string {
+	if len(e.Details) == 0 {
+		return e.text
+	}
+
+	details := make([]string, len(e.Details))
+	for i, detail := range e.Details {
+		if field, ok := detail.(FieldError); ok {
+			details[i] = field.Field + ": " + field.Message
+			continue
+		}
+		details[i] = fmt.Sprint(detail)
+	}
+
+	return e.text + ": " + strings.Join(details, ", ")
+}
+
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// WithDetails возвращает копию ошибки с дополнительными деталями, ошибка каталога не меняется.
+func (e *Error) WithDetails(details ...interface{}) *Error {
+	err := *e
+	err.Details = append(slices.Clip(e.Details), details...)
+
+	return &err
+}
+
+// WithField возвращает копию ошибки с нарушением в поле field.
+func (e *Error) WithField(field, message string) *Error {
+	return e.WithDetails(FieldError{Field: field, Message: message})
+}
@@ -0,0 +1,96 @@
+package apikey
+
+import (
+	"context"
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	"rest_clickhouse/pkg/logger"
+	"sync"
+	"time"
+)
+
+const dbTimeout = 5 * time.Second
+
+// UsageRecorder копит время последнего использования ключей в памяти и периодически
+// сохраняет его одним запросом, чтобы проверка ключа не писала в Postgres на каждый вызов.
+type UsageRecorder struct {
+	apiKeysRepository repository.ApiKeysRepository
+	interval          time.Duration
+	logger            logger.Logger
+
+	mu       sync.Mutex
+	lastUsed map[int]time.Time
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func NewUsageRecorder(apiKeysRepository repository.ApiKeysRepository, interval time.Duration, logger logger.Logger) *UsageRecorder {
+	return &UsageRecorder{
+		apiKeysRepository: apiKeysRepository,
+		interval:          interval,
+		logger:            logger,
+		lastUsed:          make(map[int]time.Time),
+		stop:              make(chan struct{}),
+		done:              make(chan struct{}),
+	}
+}
+
+func (r *UsageRecorder) Record(id int, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if at.After(r.lastUsed[id]) {
+		r.lastUsed[id] = at
+	}
+}
+
+func (r *UsageRecorder) Start() {
+	go r.flushPeriodically()
+}
+
+func (r *UsageRecorder) flushPeriodically() {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+			if err := r.flush(ctx); err != nil {
+				r.logger.ErrorF("error saving api keys last used: %v", err)
+			}
+			cancel()
+		case <-r.stop:
+			return
+		}
+	}
+}
+
+// Stop останавливает периодическое сохранение и сохраняет накопленное.
+func (r *UsageRecorder) Stop(ctx context.Context) error {
+	close(r.stop)
+	<-r.done
+
+	return r.flush(ctx)
+}
+
+func (r *UsageRecorder) flush(ctx context.Context) error {
+	r.mu.Lock()
+	lastUsed := r.lastUsed
+	r.lastUsed = make(map[int]time.Time)
+	r.mu.Unlock()
+
+	if len(lastUsed) == 0 {
+		return nil
+	}
+
+	if err := r.apiKeysRepository.UpdateLastUsed(ctx, lastUsed); err != nil {
+		// Возвращаем несохраненное, чтобы записать его при следующем сбросе.
+		for id, at := range lastUsed {
+			r.Record(id, at)
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package graphql
+
+import (
+	"context"
+	"rest_clickhouse/internal/infrastructure/usecase/interactors"
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+
+	"github.com/graph-gophers/dataloader/v7"
+)
+
+// goodsKey - страница товаров проекта. Ключи с одинаковыми параметрами страницы загружаются одним запросом.
+type goodsKey struct {
+	projectId  int
+	limit      int
+	offset     int
+	tag        string
+	categoryId int
+}
+
+// historyKey - последние limit событий товара или проекта id.
+type historyKey struct {
+	id    int
+	limit int
+}
+
+// loaders собирают обращения резолверов одного уровня запроса в пачки, чтобы вложенные поля
+// не порождали запрос на каждый проект или товар. Создаются на каждый запрос GraphQL.
+type loaders struct {
+	projects       *dataloader.Loader[int, *repository.ProjectModel]
+	goods          *dataloader.Loader[goodsKey, []*repository.GoodModel]
+	goodsCounts    *dataloader.Loader[int, *repository.GoodsCountModel]
+	projectHistory *dataloader.Loader[historyKey, []*repository.EventsModel]
+	goodHistory    *dataloader.Loader[historyKey, []*repository.EventsModel]
+}
+
+type loadersKey struct{}
+
+// newLoaders создает загрузчики запроса. Подписка живет долго, поэтому ее загрузчики не кэшируют результаты.
+func newLoaders(projectsInteractor interactors.ProjectsInteractor, cached bool) *loaders {
+	return &loaders{
+		projects: dataloader.NewBatchedLoader(
+			projectsBatch(projectsInteractor), loaderOptions[int, *repository.ProjectModel](cached)...),
+		goods: dataloader.NewBatchedLoader(
+			goodsBatch(projectsInteractor), loaderOptions[goodsKey, []*repository.GoodModel](cached)...),
+		goodsCounts: dataloader.NewBatchedLoader(
+			goodsCountsBatch(projectsInteractor), loaderOptions[int, *repository.GoodsCountModel](cached)...),
+		projectHistory: dataloader.NewBatchedLoader(
+			historyBatch(projectsInteractor, false), loaderOptions[historyKey, []*repository.EventsModel](cached)...),
+		goodHistory: dataloader.NewBatchedLoader(
+			historyBatch(projectsInteractor, true), loaderOptions[historyKey, []*repository.EventsModel](cached)...),
+	}
+}
+
+func loaderOptions[K comparable, V any](cached bool) []dataloader.Option[K, V] {
+	if cached {
+		return nil
+	}
+
+	return []dataloader.Option[K, V]{dataloader.WithCache[K, V](&dataloader.NoCache[K, V]{})}
+}
+
+func contextWithLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFromContext(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+func projectsBatch(projectsInteractor interactors.ProjectsInteractor) dataloader.BatchFunc[int, *repository.ProjectModel] {
+	return func(ctx context.Context, ids []int) []*dataloader.Result[*repository.ProjectModel] {
+		projects, err := projectsInteractor.GetProjects(ctx, ids)
+		if err != nil {
+			return failedResults[*repository.ProjectModel](len(ids), err)
+		}
+
+		byId := make(map[int]*repository.ProjectModel, len(projects))
+		for _, project := range projects {
+			byId[project.Id] = project
+		}
+
+		results := make([]*dataloader.Result[*repository.ProjectModel], len(ids))
+		for i, id := range ids {
+			results[i] = &dataloader.Result[*repository.ProjectModel]{Data: byId[id]}
+		}
+
+		return results
+	}
+}
+
+// goodsBatch группирует ключи по параметрам страницы и загружает каждую группу одним запросом.
+func goodsBatch(projectsInteractor interactors.ProjectsInteractor) dataloader.BatchFunc[goodsKey, []*repository.GoodModel] {
+	return func(ctx context.Context, keys []goodsKey) []*dataloader.Result[[]*repository.GoodModel] {
+		groups := make(map[goodsKey][]int)
+		for _, key := range keys {
+			page := key
+			page.projectId = 0
+			groups[page] = append(groups[page], key.projectId)
+		}
+
+		pages := make(map[goodsKey]map[int][]*repository.GoodModel, len(groups))
+		errs := make(map[goodsKey]error)
+		for page, projectIds := range groups {
+			pages[page], errs[page] = projectsInteractor.GetGoods(ctx, repository.ProjectGoodsQuery{
+				ProjectIds: projectIds,
+				Tag:        page.tag,
+				CategoryId: page.categoryId,
+				Limit:      page.limit,
+				Offset:     page.offset,
+			})
+		}
+
+		results := make([]*dataloader.Result[[]*repository.GoodModel], len(keys))
+		for i, key := range keys {
+			page := key
+			page.projectId = 0
+			goods := pages[page][key.projectId]
+			if goods == nil {
+				goods = []*repository.GoodModel{}
+			}
+			results[i] = &dataloader.Result[[]*repository.GoodModel]{Data: goods, Error: errs[page]}
+		}
+
+		return results
+	}
+}
+
+func goodsCountsBatch(projectsInteractor interactors.ProjectsInteractor) dataloader.BatchFunc[int, *repository.GoodsCountModel] {
+	return func(ctx context.Context, projectIds []int) []*dataloader.Result[*repository.GoodsCountModel] {
+		counts, err := projectsInteractor.CountGoods(ctx, projectIds)
+		if err != nil {
+			return failedResults[*repository.GoodsCountModel](len(projectIds), err)
+		}
+
+		results := make([]*dataloader.Result[*repository.GoodsCountModel], len(projectIds))
+		for i, projectId := range projectIds {
+			results[i] = &dataloader.Result[*repository.GoodsCountModel]{Data: counts[projectId]}
+		}
+
+		return results
+	}
+}
+
+// historyBatch загружает события товаров (byGood) или проектов, ключи группируются по limit.
+func historyBatch(projectsInteractor interactors.ProjectsInteractor, byGood bool) dataloader.BatchFunc[historyKey, []*repository.EventsModel] {
+	return func(ctx context.Context, keys []historyKey) []*dataloader.Result[[]*repository.EventsModel] {
+		groups := make(map[int][]int)
+		for _, key := range keys {
+			groups[key.limit] = append(groups[key.limit], key.id)
+		}
+
+		events := make(map[historyKey][]*repository.EventsModel, len(keys))
+		errs := make(map[int]error)
+		for limit, ids := range groups {
+			filter := repository.EventsHistoryFilter{ProjectIds: ids, Limit: limit}
+			if byGood {
+				filter = repository.EventsHistoryFilter{GoodIds: ids, Limit: limit}
+			}
+
+			history, err := projectsInteractor.GetHistory(ctx, filter)
+			if err != nil {
+				errs[limit] = err
+				continue
+			}
+			for _, event := range history {
+				id := event.ProjectId
+				if byGood {
+					id = event.Id
+				}
+				key := historyKey{id: id, limit: limit}
+				events[key] = append(events[key], event)
+			}
+		}
+
+		results := make([]*dataloader.Result[[]*repository.EventsModel], len(keys))
+		for i, key := range keys {
+			history := events[key]
+			if history == nil {
+				history = []*repository.EventsModel{}
+			}
+			results[i] = &dataloader.Result[[]*repository.EventsModel]{Data: history, Error: errs[key.limit]}
+		}
+
+		return results
+	}
+}
+
+func failedResults[V any](count int, err error) []*dataloader.Result[V] {
+	results := make([]*dataloader.Result[V], count)
+	for i := range results {
+		results[i] = &dataloader.Result[V]{Error: err}
+	}
+
+	return results
+}
@@ -0,0 +1,381 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"rest_clickhouse/internal/apperrors"
+	nats_client "rest_clickhouse/internal/infrastructure/queue/nats"
+	"rest_clickhouse/internal/infrastructure/usecase/interactors"
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	"rest_clickhouse/pkg/auth"
+	"rest_clickhouse/pkg/features"
+	"rest_clickhouse/pkg/logger"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+// JSON - скаляр для объекта JSON без схемы.
+type JSON map[string]interface{}
+
+func (JSON) ImplementsGraphQLType(name string) bool {
+	return name == "JSON"
+}
+
+func (j *JSON) UnmarshalGraphQL(input interface{}) error {
+	object, ok := input.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("wrong type for JSON: %T", input)
+	}
+	*j = object
+
+	return nil
+}
+
+type rootResolver struct {
+	goodsInteractor interactors.GoodsInteractor
+	broadcaster     *nats_client.EventBroadcaster
+	features        *features.Flags
+	logger          logger.Logger
+}
+
+func (r *rootResolver) Project(ctx context.Context, args struct{ Id int32 }) (*projectResolver, error) {
+	if err := authorizeProject(ctx, int(args.Id)); err != nil {
+		return nil, err
+	}
+
+	project, err := loadersFromContext(ctx).projects.Load(ctx, int(args.Id))()
+	if err != nil || project == nil {
+		return nil, err
+	}
+
+	return &projectResolver{project: project}, nil
+}
+
+// Projects возвращает найденные проекты в порядке ids, отсутствующие пропускаются.
+func (r *rootResolver) Projects(ctx context.Context, args struct{ Ids []int32 }) ([]*projectResolver, error) {
+	ids := make([]int, len(args.Ids))
+	for i, id := range args.Ids {
+		if err := authorizeProject(ctx, int(id)); err != nil {
+			return nil, err
+		}
+		ids[i] = int(id)
+	}
+
+	projects, errs := loadersFromContext(ctx).projects.LoadMany(ctx, ids)()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*projectResolver, 0, len(projects))
+	for _, project := range projects {
+		if project != nil {
+			resolvers = append(resolvers, &projectResolver{project: project})
+		}
+	}
+
+	return resolvers, nil
+}
+
+func (r *rootResolver) Good(ctx context.Context, args struct{ ProjectId, Id int32 }) (*goodResolver, error) {
+	if err := authorizeProject(ctx, int(args.ProjectId)); err != nil {
+		return nil, err
+	}
+
+	good, err := r.goodsInteractor.GetGood(ctx, int(args.Id), int(args.ProjectId))
+	if errors.Is(err, apperrors.GoodNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &goodResolver{good: good}, nil
+}
+
+// GoodEvents передает изменения товаров проекта, пока клиент не закроет подписку.
+func (r *rootResolver) GoodEvents(ctx context.Context, args struct{ ProjectId int32 }) (<-chan *goodEventResolver, error) {
+	if !r.features.GoodsStream() {
+		return nil, apperrors.FeatureDisabled.WithDetails("goods stream")
+	}
+
+	projectId := int(args.ProjectId)
+	if err := authorizeProject(ctx, projectId); err != nil {
+		return nil, err
+	}
+
+	_, events, unsubscribe := r.broadcaster.Subscribe(0)
+	resolvers := make(chan *goodEventResolver)
+	go func() {
+		defer close(resolvers)
+		defer unsubscribe()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+				if event.Good.ProjectId != projectId {
+					continue
+				}
+
+				goodEvent := repository.GoodEvent{GoodModel: *event.Good, AuditModel: event.Audit, Type: event.Type}
+				select {
+				case resolvers <- &goodEventResolver{event: repository.GoodEventToEvent(goodEvent)}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return resolvers, nil
+}
+
+type projectResolver struct {
+	project *repository.ProjectModel
+}
+
+func (r *projectResolver) Id() int32 {
+	return int32(r.project.Id)
+}
+
+func (r *projectResolver) Name() string {
+	return r.project.Name
+}
+
+func (r *projectResolver) Goods(ctx context.Context, args struct {
+	Limit      int32
+	Offset     int32
+	Tag        *string
+	CategoryId *int32
+}) ([]*goodResolver, error) {
+	key := goodsKey{projectId: r.project.Id, limit: int(args.Limit), offset: int(args.Offset)}
+	if args.Tag != nil {
+		key.tag = *args.Tag
+	}
+	if args.CategoryId != nil {
+		key.categoryId = int(*args.CategoryId)
+	}
+
+	goods, err := loadersFromContext(ctx).goods.Load(ctx, key)()
+	if err != nil {
+		return nil, err
+	}
+
+	resolvers := make([]*goodResolver, len(goods))
+	for i, good := range goods {
+		resolvers[i] = &goodResolver{good: good}
+	}
+
+	return resolvers, nil
+}
+
+func (r *projectResolver) GoodsCount(ctx context.Context) (int32, error) {
+	count, err := loadersFromContext(ctx).goodsCounts.Load(ctx, r.project.Id)()
+	if err != nil {
+		return 0, err
+	}
+
+	return int32(count.Total), nil
+}
+
+func (r *projectResolver) RemovedCount(ctx context.Context) (int32, error) {
+	count, err := loadersFromContext(ctx).goodsCounts.Load(ctx, r.project.Id)()
+	if err != nil {
+		return 0, err
+	}
+
+	return int32(count.Removed), nil
+}
+
+func (r *projectResolver) History(ctx context.Context, args struct{ Limit int32 }) ([]*goodEventResolver, error) {
+	events, err := loadersFromContext(ctx).projectHistory.Load(ctx, historyKey{id: r.project.Id, limit: int(args.Limit)})()
+	if err != nil {
+		return nil, err
+	}
+
+	return eventResolvers(events), nil
+}
+
+type goodResolver struct {
+	good *repository.GoodModel
+}
+
+func (r *goodResolver) Id() int32 {
+	return int32(r.good.Id)
+}
+
+func (r *goodResolver) ProjectId() int32 {
+	return int32(r.good.ProjectId)
+}
+
+func (r *goodResolver) Name() string {
+	return r.good.Name
+}
+
+func (r *goodResolver) Description() string {
+	return r.good.Description
+}
+
+func (r *goodResolver) Priority() int32 {
+	return int32(r.good.Priority)
+}
+
+func (r *goodResolver) Removed() bool {
+	return r.good.Removed
+}
+
+func (r *goodResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.good.CreatedAt}
+}
+
+func (r *goodResolver) CategoryId() *int32 {
+	if r.good.CategoryId == nil {
+		return nil
+	}
+	categoryId := int32(*r.good.CategoryId)
+
+	return &categoryId
+}
+
+func (r *goodResolver) Tags() []string {
+	if r.good.Tags == nil {
+		return []string{}
+	}
+
+	return r.good.Tags
+}
+
+func (r *goodResolver) Attributes() JSON {
+	if r.good.Attributes == nil {
+		return JSON{}
+	}
+
+	return r.good.Attributes
+}
+
+func (r *goodResolver) Project(ctx context.Context) (*projectResolver, error) {
+	project, err := loadersFromContext(ctx).projects.Load(ctx, r.good.ProjectId)()
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, apperrors.ProjectNotFound
+	}
+
+	return &projectResolver{project: project}, nil
+}
+
+func (r *goodResolver) History(ctx context.Context, args struct{ Limit int32 }) ([]*goodEventResolver, error) {
+	events, err := loadersFromContext(ctx).goodHistory.Load(ctx, historyKey{id: r.good.Id, limit: int(args.Limit)})()
+	if err != nil {
+		return nil, err
+	}
+
+	return eventResolvers(events), nil
+}
+
+type goodEventResolver struct {
+	event *repository.EventsModel
+}
+
+func eventResolvers(events []*repository.EventsModel) []*goodEventResolver {
+	resolvers := make([]*goodEventResolver, len(events))
+	for i, event := range events {
+		resolvers[i] = &goodEventResolver{event: event}
+	}
+
+	return resolvers
+}
+
+func (r *goodEventResolver) GoodId() int32 {
+	return int32(r.event.Id)
+}
+
+func (r *goodEventResolver) ProjectId() int32 {
+	return int32(r.event.ProjectId)
+}
+
+func (r *goodEventResolver) Type() string {
+	return r.event.EventType
+}
+
+func (r *goodEventResolver) Name() string {
+	return r.event.Name
+}
+
+func (r *goodEventResolver) Description() string {
+	return r.event.Description
+}
+
+func (r *goodEventResolver) Priority() int32 {
+	return int32(r.event.Priority)
+}
+
+func (r *goodEventResolver) Removed() bool {
+	return r.event.Removed
+}
+
+func (r *goodEventResolver) Tags() []string {
+	if r.event.Tags == nil {
+		return []string{}
+	}
+
+	return r.event.Tags
+}
+
+func (r *goodEventResolver) Attributes() JSON {
+	attributes := make(JSON, len(r.event.Attributes))
+	for name, value := range r.event.Attributes {
+		attributes[name] = value
+	}
+
+	return attributes
+}
+
+func (r *goodEventResolver) Actor(ctx context.Context) *string {
+	return r.audit(ctx, r.event.Audit.Actor)
+}
+
+func (r *goodEventResolver) AuthMethod(ctx context.Context) *string {
+	return r.audit(ctx, r.event.Audit.AuthMethod)
+}
+
+func (r *goodEventResolver) RequestId(ctx context.Context) *string {
+	return r.audit(ctx, r.event.Audit.RequestId)
+}
+
+// audit возвращает поле аудита только администратору проекта: кто и как менял товар,
+// видно в REST API лишь с ролью admin.
+func (r *goodEventResolver) audit(ctx context.Context, value string) *string {
+	if !allows(ctx, r.event.ProjectId, auth.RoleAdmin) {
+		return nil
+	}
+
+	return &value
+}
+
+func (r *goodEventResolver) Time() graphql.Time {
+	return graphql.Time{Time: r.event.EventTime}
+}
+
+// authorizeProject требует роль viewer в проекте.
+func authorizeProject(ctx context.Context, projectId int) error {
+	if allows(ctx, projectId, auth.RoleViewer) {
+		return nil
+	}
+
+	return apperrors.Forbidden
+}
+
+// allows сообщает, есть ли у вызывающего роль не ниже required в проекте.
+// Без аутентификации доступ не ограничивается, как и в REST API.
+func allows(ctx context.Context, projectId int, required auth.Role) bool {
+	actor, ok := auth.ActorFromContext(ctx)
+
+	return !ok || actor.Allows(projectId, required)
+}
@@ -0,0 +1,105 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"rest_clickhouse/internal/api"
+	"rest_clickhouse/internal/infrastructure/usecase/interactors"
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	"rest_clickhouse/pkg/auth"
+	"rest_clickhouse/pkg/logger/zerolog"
+	"testing"
+)
+
+// fakeProjectsInteractor отдает один проект с одним событием в истории.
+type fakeProjectsInteractor struct {
+	interactors.ProjectsInteractor
+}
+
+func (fakeProjectsInteractor) GetProjects(_ context.Context, ids []int) ([]*repository.ProjectModel, error) {
+	projects := make([]*repository.ProjectModel, len(ids))
+	for i, id := range ids {
+		projects[i] = &repository.ProjectModel{Id: id, Name: "project"}
+	}
+
+	return projects, nil
+}
+
+func (fakeProjectsInteractor) GetHistory(_ context.Context, filter repository.EventsHistoryFilter) ([]*repository.EventsModel, error) {
+	events := make([]*repository.EventsModel, len(filter.ProjectIds))
+	for i, projectId := range filter.ProjectIds {
+		events[i] = &repository.EventsModel{
+			Id:        10,
+			ProjectId: projectId,
+			Name:      "chair",
+			EventType: repository.GoodUpdatedEvent,
+			Audit:     repository.AuditModel{Actor: "user:42", AuthMethod: auth.MethodJWT, RequestId: "req-1"},
+		}
+	}
+
+	return events, nil
+}
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	log, err := zerolog.NewZeroLog(io.Discard, zerolog.Config{})
+	if err != nil {
+		t.Fatalf("error creating logger: %v", err)
+	}
+	server, err := NewServer(nil, fakeProjectsInteractor{}, nil, nil, log)
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+
+	return server
+}
+
+type historyAudit struct {
+	Actor      *string `json:"actor"`
+	AuthMethod *string `json:"authMethod"`
+	RequestId  *string `json:"requestId"`
+}
+
+func queryHistoryAudit(t *testing.T, actor *auth.Actor) historyAudit {
+	t.Helper()
+
+	ctx := auth.ContextWithActor(context.Background(), actor)
+	response := newTestServer(t).Exec(ctx, api.GraphQLRequest{
+		Query: `{ project(id: 1) { history { type actor authMethod requestId } } }`,
+	})
+	if len(response.Errors) > 0 {
+		t.Fatalf("unexpected errors: %+v", response.Errors)
+	}
+
+	var data struct {
+		Project struct {
+			History []historyAudit `json:"history"`
+		} `json:"project"`
+	}
+	if err := json.Unmarshal(response.Data, &data); err != nil {
+		t.Fatalf("error parsing data: %v", err)
+	}
+	if len(data.Project.History) != 1 {
+		t.Fatalf("history %s, want one event", response.Data)
+	}
+
+	return data.Project.History[0]
+}
+
+func TestEventAuditHiddenFromViewer(t *testing.T) {
+	event := queryHistoryAudit(t, &auth.Actor{Id: "viewer", Method: auth.MethodJWT, Projects: map[int]auth.Role{1: auth.RoleViewer}})
+
+	if event.Actor != nil || event.AuthMethod != nil || event.RequestId != nil {
+		t.Errorf("viewer got audit fields %+v, want null", event)
+	}
+}
+
+func TestEventAuditVisibleToAdmin(t *testing.T) {
+	event := queryHistoryAudit(t, &auth.Actor{Id: "admin", Method: auth.MethodJWT, Projects: map[int]auth.Role{1: auth.RoleAdmin}})
+
+	if event.Actor == nil || *event.Actor != "user:42" || event.RequestId == nil || *event.RequestId != "req-1" {
+		t.Errorf("admin got audit fields %+v, want actor user:42 and request req-1", event)
+	}
+}
@@ -0,0 +1,136 @@
+package graphql
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"rest_clickhouse/internal/api"
+	"rest_clickhouse/internal/apperrors"
+	nats_client "rest_clickhouse/internal/infrastructure/queue/nats"
+	"rest_clickhouse/internal/infrastructure/usecase/interactors"
+	"rest_clickhouse/pkg/features"
+	"rest_clickhouse/pkg/logger"
+
+	"github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+)
+
+const (
+	// maxQueryDepth ограничивает вложенность запроса: project { goods { project { goods ... } } }.
+	maxQueryDepth = 8
+	// maxParallelism должен покрывать поля одного уровня: резолвер, ждущий пачку загрузчика, занимает слот,
+	// и при нехватке слотов пачка уходит без остальных ключей.
+	maxParallelism = 100
+)
+
+//go:embed schema.graphql
+var schemaSource string
+
+// Server исполняет запросы GraphQL над проектами и товарами.
+type Server struct {
+	schema             *graphql.Schema
+	projectsInteractor interactors.ProjectsInteractor
+	logger             logger.Logger
+}
+
+func NewServer(
+	goodsInteractor interactors.GoodsInteractor,
+	projectsInteractor interactors.ProjectsInteractor,
+	broadcaster *nats_client.EventBroadcaster,
+	features *features.Flags,
+	logger logger.Logger,
+) (*Server, error) {
+	resolver := &rootResolver{
+		goodsInteractor: goodsInteractor,
+		broadcaster:     broadcaster,
+		features:        features,
+		logger:          logger,
+	}
+
+	schema, err := graphql.ParseSchema(schemaSource, resolver, graphql.MaxDepth(maxQueryDepth), graphql.MaxParallelism(maxParallelism))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing graphql schema: %w", err)
+	}
+
+	return &Server{
+		schema:             schema,
+		projectsInteractor: projectsInteractor,
+		logger:             logger,
+	}, nil
+}
+
+// Exec исполняет запрос или мутацию. Подписка исполняется только через Subscribe.
+func (s *Server) Exec(ctx context.Context, request api.GraphQLRequest) *api.GraphQLResponse {
+	ctx = contextWithLoaders(ctx, newLoaders(s.projectsInteractor, true))
+	response := s.schema.Exec(ctx, request.Query, request.OperationName, request.Variables)
+
+	return s.response(ctx, response)
+}
+
+// Subscribe исполняет операцию любого типа и передает ответы в канал: для запроса - один ответ,
+// для подписки - ответ на каждое событие. Канал закрывается по завершении операции или отмене ctx.
+func (s *Server) Subscribe(ctx context.Context, request api.GraphQLRequest) (<-chan *api.GraphQLResponse, error) {
+	ctx = contextWithLoaders(ctx, newLoaders(s.projectsInteractor, false))
+	results, err := s.schema.Subscribe(ctx, request.Query, request.OperationName, request.Variables)
+	if err != nil {
+		return nil, fmt.Errorf("error subscribing: %w", err)
+	}
+
+	responses := make(chan *api.GraphQLResponse)
+	go func() {
+		defer close(responses)
+		for result := range results {
+			response, ok := result.(*graphql.Response)
+			if !ok {
+				continue
+			}
+			select {
+			case responses <- s.response(ctx, response):
+			case <-ctx.Done():
+				// Дочитываем канал, чтобы исполнитель подписки завершился.
+				for range results {
+				}
+				return
+			}
+		}
+	}()
+
+	return responses, nil
+}
+
+// response переводит ответ в api.GraphQLResponse. Ошибки резолверов отдаются как ошибки предметной области:
+// ключ сообщения и код в extensions, остальные ошибки логируются и отдаются как внутренние.
+func (s *Server) response(ctx context.Context, response *graphql.Response) *api.GraphQLResponse {
+	result := &api.GraphQLResponse{Data: response.Data}
+	for _, queryErr := range response.Errors {
+		result.Errors = append(result.Errors, s.queryError(ctx, queryErr))
+	}
+
+	return result
+}
+
+func (s *Server) queryError(ctx context.Context, queryErr *gqlerrors.QueryError) api.GraphQLError {
+	gqlErr := api.GraphQLError{
+		Message: queryErr.Message,
+		Path:    queryErr.Path,
+	}
+	for _, location := range queryErr.Locations {
+		gqlErr.Locations = append(gqlErr.Locations, api.GraphQLLocation{Line: location.Line, Column: location.Column})
+	}
+
+	// Ошибки разбора и валидации запроса не связаны с резолверами и отдаются как есть.
+	if queryErr.ResolverError == nil {
+		return gqlErr
+	}
+
+	var domainErr *apperrors.Error
+	if !errors.As(queryErr.ResolverError, &domainErr) || domainErr.Kind == apperrors.KindInternal {
+		s.logger.WithContext(ctx).ErrorF("error resolving graphql field %v: %v", queryErr.Path, queryErr.ResolverError)
+		domainErr = apperrors.Internal
+	}
+	gqlErr.Message = domainErr.Message
+	gqlErr.Extensions = &api.GraphQLErrorExtensions{Code: domainErr.Code, Details: domainErr.Details}
+
+	return gqlErr
+}
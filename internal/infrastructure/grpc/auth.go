@@ -0,0 +1,103 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"rest_clickhouse/internal/apperrors"
+	"rest_clickhouse/internal/infrastructure/usecase/interactors"
+	"rest_clickhouse/pkg/auth"
+	"rest_clickhouse/pkg/logger"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	bearerPrefix    = "Bearer "
+	authorizationMD = "authorization"
+	apiKeyMD        = "x-api-key"
+)
+
+// authenticator проверяет вызывающего так же, как HTTP сервер: API ключ из метаданных x-api-key
+// или Bearer JWT из authorization.
+type authenticator struct {
+	verifier *auth.JWTVerifier
+	apiKeys  interactors.ApiKeysInteractor
+	logger   logger.Logger
+}
+
+func (a *authenticator) unaryInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := a.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (a *authenticator) streamInterceptor(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+}
+
+// authenticate сохраняет вызывающего в контексте или возвращает статус Unauthenticated.
+func (a *authenticator) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var actor *auth.Actor
+	if key := firstValue(md, apiKeyMD); key != "" && a.apiKeys != nil {
+		var err error
+		actor, err = a.apiKeys.Authenticate(ctx, key)
+		if errors.Is(err, interactors.ErrInvalidApiKey) {
+			return nil, unauthenticated()
+		}
+		if err != nil {
+			a.logger.ErrorF("grpc: error authenticating api key: %v", err)
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+	} else {
+		token, ok := strings.CutPrefix(firstValue(md, authorizationMD), bearerPrefix)
+		if !ok || token == "" {
+			return nil, unauthenticated()
+		}
+
+		var err error
+		actor, err = a.verifier.Verify(token)
+		if err != nil {
+			return nil, unauthenticated()
+		}
+	}
+
+	return auth.ContextWithActor(ctx, actor), nil
+}
+
+func unauthenticated() error {
+	return status.Error(codes.Unauthenticated, apperrors.Unauthorized.Error())
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
+
+// authorizeProject проверяет, что у вызывающего есть роль не ниже required в проекте.
+// Без вызывающего в контексте (аутентификация отключена) доступ не ограничивается.
+// projectId 0 означает все проекты и разрешен только с ролью для "*".
+func authorizeProject(ctx context.Context, projectId int, required auth.Role) error {
+	actor, ok := auth.ActorFromContext(ctx)
+	if !ok || actor.Allows(projectId, required) {
+		return nil
+	}
+
+	return apperrors.Forbidden
+}
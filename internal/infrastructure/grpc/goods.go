@@ -0,0 +1,273 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"rest_clickhouse/internal/api"
+	"rest_clickhouse/internal/apperrors"
+	"rest_clickhouse/internal/infrastructure/grpc/goodspb"
+	"rest_clickhouse/internal/infrastructure/queue"
+	nats_client "rest_clickhouse/internal/infrastructure/queue/nats"
+	"rest_clickhouse/internal/infrastructure/usecase/interactors"
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	"rest_clickhouse/pkg/auth"
+	"rest_clickhouse/pkg/logger"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	watchBufferSize   = 64
+	watchUnsubTimeout = 5 * time.Second
+)
+
+type goodsServer struct {
+	goodspb.UnimplementedGoodsServiceServer
+
+	goodsInteractor interactors.GoodsInteractor
+	sub             queue.Subscriber
+	logger          logger.Logger
+}
+
+func NewGoodsServer(goodsInteractor interactors.GoodsInteractor, sub queue.Subscriber, logger logger.Logger) goodspb.GoodsServiceServer {
+	return &goodsServer{
+		goodsInteractor: goodsInteractor,
+		sub:             sub,
+		logger:          logger,
+	}
+}
+
+func (s *goodsServer) CreateGood(ctx context.Context, req *goodspb.CreateGoodRequest) (*goodspb.Good, error) {
+	if err := authorizeProject(ctx, int(req.GetProjectId()), auth.RoleEditor); err != nil {
+		return nil, s.toStatus(err)
+	}
+
+	good := &api.Good{
+		ProjectId:   int(req.GetProjectId()),
+		Name:        req.GetName(),
+		Description: req.GetDescription(),
+		Priority:    optionalInt(req.Priority),
+		Attributes:  structToMap(req.GetAttributes()),
+	}
+	if err := api.Validate(good); err != nil {
+		return nil, s.toStatus(err)
+	}
+
+	goodModel, err := s.goodsInteractor.CreateGood(ctx, good)
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+
+	return toProtoGood(goodModel), nil
+}
+
+func (s *goodsServer) GetGood(ctx context.Context, req *goodspb.GetGoodRequest) (*goodspb.Good, error) {
+	if err := authorizeProject(ctx, int(req.GetProjectId()), auth.RoleViewer); err != nil {
+		return nil, s.toStatus(err)
+	}
+
+	goodModel, err := s.goodsInteractor.GetGood(ctx, int(req.GetId()), int(req.GetProjectId()))
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+
+	return toProtoGood(goodModel), nil
+}
+
+func (s *goodsServer) ListGoods(ctx context.Context, req *goodspb.ListGoodsRequest) (*goodspb.ListGoodsResponse, error) {
+	if err := authorizeProject(ctx, int(req.GetProjectId()), auth.RoleViewer); err != nil {
+		return nil, s.toStatus(err)
+	}
+
+	goodModelList, err := s.goodsInteractor.GetList(ctx, int(req.GetLimit()), int(req.GetOffset()), api.GoodListFilter{
+		ProjectId:  int(req.GetProjectId()),
+		Tag:        req.GetTag(),
+		CategoryId: int(req.GetCategoryId()),
+		Attributes: req.GetAttributes(),
+	})
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+
+	response := &goodspb.ListGoodsResponse{
+		Meta: &goodspb.Meta{
+			Total:   int64(goodModelList.Meta.Total),
+			Removed: int64(goodModelList.Meta.Removed),
+			Limit:   int64(goodModelList.Meta.Limit),
+			Offset:  int64(goodModelList.Meta.Offset),
+		},
+		Goods: make([]*goodspb.Good, len(goodModelList.Goods)),
+	}
+	for i, goodModel := range goodModelList.Goods {
+		response.Goods[i] = toProtoGood(goodModel)
+	}
+
+	return response, nil
+}
+
+func (s *goodsServer) UpdateGood(ctx context.Context, req *goodspb.UpdateGoodRequest) (*goodspb.Good, error) {
+	if err := authorizeProject(ctx, int(req.GetProjectId()), auth.RoleEditor); err != nil {
+		return nil, s.toStatus(err)
+	}
+
+	// Меняются только переданные поля, как в PATCH.
+	patch := &api.GoodPatch{
+		Id:          int(req.GetId()),
+		ProjectId:   int(req.GetProjectId()),
+		Name:        req.Name,
+		Description: req.Description,
+		Priority:    optionalInt(req.Priority),
+		Attributes:  structToMap(req.GetAttributes()),
+	}
+	if err := api.Validate(patch); err != nil {
+		return nil, s.toStatus(err)
+	}
+
+	goodModel, err := s.goodsInteractor.PatchGood(ctx, patch)
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+
+	return toProtoGood(goodModel), nil
+}
+
+func (s *goodsServer) RemoveGood(ctx context.Context, req *goodspb.RemoveGoodRequest) (*goodspb.Good, error) {
+	if err := authorizeProject(ctx, int(req.GetProjectId()), auth.RoleEditor); err != nil {
+		return nil, s.toStatus(err)
+	}
+
+	goodModel, err := s.goodsInteractor.RemoveGood(ctx, &api.Good{
+		Id:        int(req.GetId()),
+		ProjectId: int(req.GetProjectId()),
+	})
+	if err != nil {
+		return nil, s.toStatus(err)
+	}
+
+	return toProtoGood(goodModel), nil
+}
+
+// WatchGoods подписывается на топик событий и отправляет клиенту изменения товаров,
+// пока клиент не отключится. При медленном клиенте события отбрасываются.
+func (s *goodsServer) WatchGoods(req *goodspb.WatchGoodsRequest, stream goodspb.GoodsService_WatchGoodsServer) error {
+	if err := authorizeProject(stream.Context(), int(req.GetProjectId()), auth.RoleViewer); err != nil {
+		return s.toStatus(err)
+	}
+
+	goods := make(chan *repository.GoodModel, watchBufferSize)
+
+	unsub, err := s.sub.Sub(nats_client.EventTopicName, func(m *nats.Msg) {
+		var goodModel repository.GoodModel
+		if err := json.Unmarshal(m.Data, &goodModel); err != nil {
+			s.logger.ErrorF("error unmarshaling event: %v", err)
+			return
+		}
+
+		if req.GetProjectId() != 0 && int64(goodModel.ProjectId) != req.GetProjectId() {
+			return
+		}
+
+		select {
+		case goods <- &goodModel:
+		default:
+			s.logger.WarnF("watch goods: client is too slow, event for good %d dropped", goodModel.Id)
+		}
+	})
+	if err != nil {
+		return status.Error(codes.Unavailable, "could not subscribe to events")
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), watchUnsubTimeout)
+		defer cancel()
+		if err := unsub(ctx); err != nil {
+			s.logger.ErrorF("error on unsubscribe: %v", err)
+		}
+	}()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case goodModel := <-goods:
+			if err := stream.Send(toProtoGood(goodModel)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// grpcCodes сопоставляет виды ошибок предметной области кодам gRPC.
+var grpcCodes = map[apperrors.Kind]codes.Code{
+	apperrors.KindNotFound:             codes.NotFound,
+	apperrors.KindConflict:             codes.AlreadyExists,
+	apperrors.KindValidation:           codes.InvalidArgument,
+	apperrors.KindUnavailable:          codes.Unavailable,
+	apperrors.KindUnauthorized:         codes.Unauthenticated,
+	apperrors.KindForbidden:            codes.PermissionDenied,
+	apperrors.KindTooManyRequests:      codes.ResourceExhausted,
+	apperrors.KindNotAcceptable:        codes.InvalidArgument,
+	apperrors.KindUnsupportedMediaType: codes.InvalidArgument,
+}
+
+func (s *goodsServer) toStatus(err error) error {
+	var domainErr *apperrors.Error
+	if errors.As(err, &domainErr) {
+		if code, ok := grpcCodes[domainErr.Kind]; ok {
+			return status.Error(code, domainErr.Error())
+		}
+	}
+
+	s.logger.ErrorF("grpc: %v", err)
+	return status.Error(codes.Internal, "internal error")
+}
+
+func toProtoGood(goodModel *repository.GoodModel) *goodspb.Good {
+	good := &goodspb.Good{
+		Id:          int64(goodModel.Id),
+		ProjectId:   int64(goodModel.ProjectId),
+		Name:        goodModel.Name,
+		Description: goodModel.Description,
+		Priority:    int64(goodModel.Priority),
+		Removed:     goodModel.Removed,
+		CreatedAt:   timestamppb.New(goodModel.CreatedAt),
+		Tags:        goodModel.Tags,
+	}
+
+	if goodModel.CategoryId != nil {
+		categoryId := int64(*goodModel.CategoryId)
+		good.CategoryId = &categoryId
+	}
+
+	if len(goodModel.Attributes) > 0 {
+		if attributes, err := structpb.NewStruct(goodModel.Attributes); err == nil {
+			good.Attributes = attributes
+		}
+	}
+
+	return good
+}
+
+// optionalInt переводит необязательное поле запроса в указатель, nil означает отсутствующее поле.
+func optionalInt(value *int32) *int {
+	if value == nil {
+		return nil
+	}
+
+	v := int(*value)
+	return &v
+}
+
+// structToMap возвращает nil для отсутствующих атрибутов, чтобы обновление их не затрагивало.
+func structToMap(attributes *structpb.Struct) map[string]interface{} {
+	if attributes == nil {
+		return nil
+	}
+
+	return attributes.AsMap()
+}
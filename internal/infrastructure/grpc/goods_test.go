@@ -0,0 +1,244 @@
+package grpc
+
+import (
+	"context"
+	"io"
+	"net"
+	"rest_clickhouse/internal/infrastructure/grpc/goodspb"
+	"rest_clickhouse/internal/infrastructure/queue"
+	"rest_clickhouse/internal/infrastructure/usecase/interactors"
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	"rest_clickhouse/pkg/logger/zerolog"
+	"sync"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// fakeGoodsRepository хранит товары в памяти и применяет изменения так же, как Update в Postgres:
+// поля со значением nil не меняются.
+type fakeGoodsRepository struct {
+	repository.GoodsRepository
+
+	mu    sync.Mutex
+	goods map[int]*repository.GoodModel
+}
+
+func (r *fakeGoodsRepository) Create(_ context.Context, good *repository.GoodModel) (*repository.GoodModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	created := *good
+	created.Id = len(r.goods) + 1
+	r.goods[created.Id] = &created
+
+	result := created
+	return &result, nil
+}
+
+func (r *fakeGoodsRepository) Get(_ context.Context, id, _ int) (*repository.GoodModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	good := *r.goods[id]
+	return &good, nil
+}
+
+func (r *fakeGoodsRepository) Update(_ context.Context, update *repository.GoodUpdateModel) (*repository.GoodModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	good := r.goods[update.Id]
+	if update.Name != nil {
+		good.Name = *update.Name
+	}
+	if update.Description != nil {
+		good.Description = *update.Description
+	}
+	if update.Priority != nil {
+		good.Priority = *update.Priority
+	}
+	if update.Attributes != nil {
+		good.Attributes = update.Attributes
+	}
+	if update.MergeAttributes != nil {
+		attributes, err := update.MergeAttributes(good.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		good.Attributes = attributes
+	}
+
+	result := *good
+	return &result, nil
+}
+
+type fakeAttributesRepository struct {
+	repository.AttributesRepository
+}
+
+func (fakeAttributesRepository) GetList(context.Context, int) ([]*repository.AttributeModel, error) {
+	return []*repository.AttributeModel{
+		{Name: "color", Type: repository.AttributeTypeString},
+		{Name: "size", Type: repository.AttributeTypeString},
+	}, nil
+}
+
+type fakePubSub struct {
+	queue.PubSub
+}
+
+func (fakePubSub) Pub(context.Context, string, []byte) error {
+	return nil
+}
+
+func (fakePubSub) Sub(string, func(m *nats.Msg)) (func(ctx context.Context) error, error) {
+	return func(context.Context) error { return nil }, nil
+}
+
+// newTestClient запускает gRPC сервер поверх настоящего GoodsInteractor с репозиториями в памяти.
+// Аутентификация отключена, как при пустом JWT_SECRET.
+func newTestClient(t *testing.T) goodspb.GoodsServiceClient {
+	t.Helper()
+
+	log, err := zerolog.NewZeroLog(io.Discard, zerolog.Config{})
+	if err != nil {
+		t.Fatalf("error creating logger: %v", err)
+	}
+	goodsInteractor := interactors.NewGoodsInteractor(
+		&fakeGoodsRepository{goods: map[int]*repository.GoodModel{}},
+		fakeAttributesRepository{}, nil, fakePubSub{}, nil, nil, interactors.Timeouts{}, log,
+	)
+	server := NewGoodsGRPCServer("0", NewGoodsServer(goodsInteractor, fakePubSub{}, log), nil, nil, log)
+
+	listener := bufconn.Listen(1 << 20)
+	go server.server.Serve(listener)
+	t.Cleanup(server.server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("error dialing server: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return goodspb.NewGoodsServiceClient(conn)
+}
+
+func TestCreateGoodPersistsDescriptionAndPriority(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	good, err := client.CreateGood(ctx, &goodspb.CreateGoodRequest{
+		ProjectId:   1,
+		Name:        "chair",
+		Description: proto.String("wooden"),
+		Priority:    proto.Int32(7),
+	})
+	if err != nil {
+		t.Fatalf("error creating good: %v", err)
+	}
+	if good.GetDescription() != "wooden" || good.GetPriority() != 7 {
+		t.Errorf("created good %v, want description wooden and priority 7", good)
+	}
+
+	good, err = client.GetGood(ctx, &goodspb.GetGoodRequest{Id: good.GetId(), ProjectId: 1})
+	if err != nil {
+		t.Fatalf("error getting good: %v", err)
+	}
+	if good.GetDescription() != "wooden" || good.GetPriority() != 7 {
+		t.Errorf("stored good %v, want description wooden and priority 7", good)
+	}
+}
+
+func TestCreateGoodDefaultPriority(t *testing.T) {
+	client := newTestClient(t)
+
+	good, err := client.CreateGood(context.Background(), &goodspb.CreateGoodRequest{ProjectId: 1, Name: "chair"})
+	if err != nil {
+		t.Fatalf("error creating good: %v", err)
+	}
+	if good.GetPriority() != repository.GoodDefaultPriority {
+		t.Errorf("priority %d, want %d", good.GetPriority(), repository.GoodDefaultPriority)
+	}
+}
+
+func TestCreateGoodValidatesPriority(t *testing.T) {
+	client := newTestClient(t)
+
+	_, err := client.CreateGood(context.Background(), &goodspb.CreateGoodRequest{
+		ProjectId: 1,
+		Name:      "chair",
+		Priority:  proto.Int32(-1),
+	})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("error %v, want %s", err, codes.InvalidArgument)
+	}
+}
+
+func TestUpdateGoodChangesOnlyPassedFields(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	good, err := client.CreateGood(ctx, &goodspb.CreateGoodRequest{
+		ProjectId:   1,
+		Name:        "chair",
+		Description: proto.String("wooden"),
+		Priority:    proto.Int32(7),
+	})
+	if err != nil {
+		t.Fatalf("error creating good: %v", err)
+	}
+
+	good, err = client.UpdateGood(ctx, &goodspb.UpdateGoodRequest{Id: good.GetId(), ProjectId: 1, Priority: proto.Int32(3)})
+	if err != nil {
+		t.Fatalf("error updating priority: %v", err)
+	}
+	if good.GetName() != "chair" || good.GetDescription() != "wooden" || good.GetPriority() != 3 {
+		t.Errorf("good after priority update %v, want chair, wooden, 3", good)
+	}
+
+	good, err = client.UpdateGood(ctx, &goodspb.UpdateGoodRequest{Id: good.GetId(), ProjectId: 1, Description: proto.String("")})
+	if err != nil {
+		t.Fatalf("error clearing description: %v", err)
+	}
+	if good.GetName() != "chair" || good.GetDescription() != "" || good.GetPriority() != 3 {
+		t.Errorf("good after clearing description %v, want chair, empty description, 3", good)
+	}
+
+	_, err = client.UpdateGood(ctx, &goodspb.UpdateGoodRequest{Id: good.GetId(), ProjectId: 1, Name: proto.String(" ")})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("error on blank name %v, want %s", err, codes.InvalidArgument)
+	}
+}
+
+func TestUpdateGoodMergesAttributes(t *testing.T) {
+	client := newTestClient(t)
+	ctx := context.Background()
+
+	attributes, _ := structpb.NewStruct(map[string]interface{}{"color": "red", "size": "L"})
+	good, err := client.CreateGood(ctx, &goodspb.CreateGoodRequest{ProjectId: 1, Name: "chair", Attributes: attributes})
+	if err != nil {
+		t.Fatalf("error creating good: %v", err)
+	}
+
+	patch, _ := structpb.NewStruct(map[string]interface{}{"color": "blue", "size": nil})
+	good, err = client.UpdateGood(ctx, &goodspb.UpdateGoodRequest{Id: good.GetId(), ProjectId: 1, Attributes: patch})
+	if err != nil {
+		t.Fatalf("error updating attributes: %v", err)
+	}
+	if got := good.GetAttributes().AsMap(); len(got) != 1 || got["color"] != "blue" {
+		t.Errorf("attributes %v, want only color blue", got)
+	}
+}
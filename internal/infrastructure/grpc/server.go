@@ -0,0 +1,131 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"rest_clickhouse/internal/infrastructure/grpc/goodspb"
+	"rest_clickhouse/internal/infrastructure/usecase/interactors"
+	"rest_clickhouse/pkg/audit"
+	"rest_clickhouse/pkg/auth"
+	"rest_clickhouse/pkg/logger"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+type GRPCServer interface {
+	Start() error
+	Stop(ctx context.Context)
+}
+
+type GoodsGRPCServer struct {
+	server     *grpc.Server
+	serverPort string
+	logger     logger.Logger
+	// streamsCtx отменяется при остановке и завершает открытые потоки, которых иначе ждал бы GracefulStop.
+	streamsCtx    context.Context
+	cancelStreams context.CancelFunc
+}
+
+func NewGoodsGRPCServer(
+	ServerPort string,
+	goodsServer goodspb.GoodsServiceServer,
+	verifier *auth.JWTVerifier,
+	apiKeys interactors.ApiKeysInteractor,
+	logger logger.Logger,
+) *GoodsGRPCServer {
+	streamsCtx, cancelStreams := context.WithCancel(context.Background())
+	server := &GoodsGRPCServer{
+		serverPort:    ServerPort,
+		logger:        logger,
+		streamsCtx:    streamsCtx,
+		cancelStreams: cancelStreams,
+	}
+	unary := []grpc.UnaryServerInterceptor{clientInterceptor}
+	stream := []grpc.StreamServerInterceptor{server.streamInterceptor}
+	// Без верификатора аутентификация отключена, как и в HTTP сервере.
+	if verifier != nil {
+		authenticator := &authenticator{verifier: verifier, apiKeys: apiKeys, logger: logger}
+		unary = append(unary, authenticator.unaryInterceptor)
+		stream = append(stream, authenticator.streamInterceptor)
+	}
+	server.server = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	)
+	goodspb.RegisterGoodsServiceServer(server.server, goodsServer)
+
+	return server
+}
+
+// Start занимает порт сразу, чтобы ошибка прослушивания остановила запуск приложения, и обслуживает вызовы в фоне.
+func (s *GoodsGRPCServer) Start() error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%v", s.serverPort))
+	if err != nil {
+		return fmt.Errorf("gRPC listen error: %w", err)
+	}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil {
+			s.logger.Error("gRPC error:", err)
+		}
+	}()
+
+	return nil
+}
+
+// Stop завершает открытые потоки и дожидается активных вызовов, по истечении ctx обрывает их.
+func (s *GoodsGRPCServer) Stop(ctx context.Context) {
+	s.cancelStreams()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.server.Stop()
+	}
+}
+
+// streamInterceptor связывает контекст потока с остановкой сервера.
+func (s *GoodsGRPCServer) streamInterceptor(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, cancel := context.WithCancel(ss.Context())
+	defer cancel()
+	stop := context.AfterFunc(s.streamsCtx, cancel)
+	defer stop()
+
+	return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+}
+
+// serverStream подменяет контекст потока.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// clientInterceptor сохраняет адрес и User-Agent вызывающего для журнала изменений.
+func clientInterceptor(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	var client audit.Client
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		client.IP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(client.IP); err == nil {
+			client.IP = host
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		client.UserAgent = strings.Join(md.Get("user-agent"), " ")
+	}
+
+	return handler(audit.ContextWithClient(ctx, client), req)
+}
@@ -0,0 +1,119 @@
+package http
+
+import (
+	"net/http"
+	"rest_clickhouse/internal/api"
+	"rest_clickhouse/internal/infrastructure/usecase/interactors"
+	"rest_clickhouse/pkg/auth"
+	"rest_clickhouse/pkg/logger"
+
+	"github.com/labstack/echo/v4"
+)
+
+type ApiKeysService interface {
+	HandleCreateApiKey(ctx echo.Context) error
+	HandleGetApiKeys(ctx echo.Context) error
+	HandleRevokeApiKey(ctx echo.Context) error
+	HandleRotateApiKey(ctx echo.Context) error
+}
+
+type apiKeysService struct {
+	apiKeysInteractor interactors.ApiKeysInteractor
+	logger            logger.Logger
+}
+
+func NewApiKeysService(apiKeysInteractor interactors.ApiKeysInteractor, logger logger.Logger) ApiKeysService {
+	return &apiKeysService{
+		apiKeysInteractor: apiKeysInteractor,
+		logger:            logger,
+	}
+}
+
+func (c *apiKeysService) HandleCreateApiKey(ctx echo.Context) error {
+	apiKey := new(api.ApiKey)
+	projectId, err := pathParamInt(ctx, "projectId")
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeProject(ctx, projectId, auth.RoleAdmin); err != nil {
+		return err
+	}
+
+	if err := bindBody(ctx, apiKey); err != nil {
+		return err
+	}
+
+	apiKey.ProjectId = projectId
+
+	apiKeyDTO, err := c.apiKeysInteractor.CreateApiKey(ctx.Request().Context(), apiKey)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusCreated, api.GetCreatedApiKey(apiKeyDTO))
+}
+
+func (c *apiKeysService) HandleGetApiKeys(ctx echo.Context) error {
+	projectId, err := pathParamInt(ctx, "projectId")
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeProject(ctx, projectId, auth.RoleAdmin); err != nil {
+		return err
+	}
+
+	apiKeyModels, err := c.apiKeysInteractor.GetList(ctx.Request().Context(), projectId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, api.GetApiKeyList(apiKeyModels))
+}
+
+func (c *apiKeysService) HandleRevokeApiKey(ctx echo.Context) error {
+	id, err := pathParamInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	projectId, err := pathParamInt(ctx, "projectId")
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeProject(ctx, projectId, auth.RoleAdmin); err != nil {
+		return err
+	}
+
+	apiKeyDTO, err := c.apiKeysInteractor.RevokeApiKey(ctx.Request().Context(), id, projectId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, api.GetApiKey(apiKeyDTO))
+}
+
+func (c *apiKeysService) HandleRotateApiKey(ctx echo.Context) error {
+	id, err := pathParamInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	projectId, err := pathParamInt(ctx, "projectId")
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeProject(ctx, projectId, auth.RoleAdmin); err != nil {
+		return err
+	}
+
+	apiKeyDTO, err := c.apiKeysInteractor.RotateApiKey(ctx.Request().Context(), id, projectId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, api.GetCreatedApiKey(apiKeyDTO))
+}
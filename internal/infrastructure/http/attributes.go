@@ -0,0 +1,90 @@
+package http
+
+import (
+	"net/http"
+	"rest_clickhouse/internal/api"
+	"rest_clickhouse/internal/infrastructure/usecase/interactors"
+	"rest_clickhouse/pkg/auth"
+	"rest_clickhouse/pkg/logger"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AttributesService interface {
+	HandleCreateAttribute(ctx echo.Context) error
+	HandleGetAttributes(ctx echo.Context) error
+	HandleRemoveAttribute(ctx echo.Context) error
+}
+
+type attributesService struct {
+	attributesInteractor interactors.AttributesInteractor
+	logger               logger.Logger
+}
+
+func NewAttributesService(attributesInteractor interactors.AttributesInteractor, logger logger.Logger) AttributesService {
+	return &attributesService{
+		attributesInteractor: attributesInteractor,
+		logger:               logger,
+	}
+}
+
+func (c *attributesService) HandleCreateAttribute(ctx echo.Context) error {
+	attribute := new(api.Attribute)
+	projectId, err := pathParamInt(ctx, "projectId")
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeProject(ctx, projectId, auth.RoleAdmin); err != nil {
+		return err
+	}
+
+	if err := bindBody(ctx, attribute); err != nil {
+		return err
+	}
+
+	attribute.ProjectId = projectId
+
+	attributeDTO, err := c.attributesInteractor.CreateAttribute(ctx.Request().Context(), attribute)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusCreated, api.GetAttribute(attributeDTO))
+}
+
+func (c *attributesService) HandleGetAttributes(ctx echo.Context) error {
+	projectId, err := pathParamInt(ctx, "projectId")
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeProject(ctx, projectId, auth.RoleViewer); err != nil {
+		return err
+	}
+
+	attributeModels, err := c.attributesInteractor.GetList(ctx.Request().Context(), projectId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, api.GetAttributeList(attributeModels))
+}
+
+func (c *attributesService) HandleRemoveAttribute(ctx echo.Context) error {
+	projectId, err := pathParamInt(ctx, "projectId")
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeProject(ctx, projectId, auth.RoleAdmin); err != nil {
+		return err
+	}
+
+	err = c.attributesInteractor.RemoveAttribute(ctx.Request().Context(), ctx.Param("name"), projectId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,74 @@
+package http
+
+import (
+	"net/http"
+	"rest_clickhouse/internal/api"
+	"rest_clickhouse/internal/infrastructure/usecase/interactors"
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	"rest_clickhouse/pkg/auth"
+	"rest_clickhouse/pkg/logger"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AuditService interface {
+	HandleGetAudit(ctx echo.Context) error
+}
+
+type auditService struct {
+	auditInteractor interactors.AuditInteractor
+	logger          logger.Logger
+}
+
+func NewAuditService(auditInteractor interactors.AuditInteractor, logger logger.Logger) AuditService {
+	return &auditService{
+		auditInteractor: auditInteractor,
+		logger:          logger,
+	}
+}
+
+// HandleGetAudit отдает журнал изменений товаров. Без projectId нужна роль admin во всех проектах.
+func (c *auditService) HandleGetAudit(ctx echo.Context) error {
+	projectId, err := queryParamInt(ctx, "projectId")
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeProject(ctx, projectId, auth.RoleAdmin); err != nil {
+		return err
+	}
+
+	from, err := queryParamTime(ctx, "from")
+	if err != nil {
+		return err
+	}
+
+	to, err := queryParamTime(ctx, "to")
+	if err != nil {
+		return err
+	}
+
+	limit, err := queryParamInt(ctx, "limit")
+	if err != nil {
+		return err
+	}
+
+	offset, err := queryParamInt(ctx, "offset")
+	if err != nil {
+		return err
+	}
+
+	records, err := c.auditInteractor.GetList(ctx.Request().Context(), repository.AuditFilter{
+		Actor:     ctx.QueryParam("actor"),
+		ProjectId: projectId,
+		From:      from,
+		To:        to,
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, api.GetAuditList(records))
+}
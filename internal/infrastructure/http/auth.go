@@ -0,0 +1,75 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"rest_clickhouse/internal/apperrors"
+	"rest_clickhouse/internal/infrastructure/usecase/interactors"
+	"rest_clickhouse/pkg/auth"
+	"rest_clickhouse/pkg/logger"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	bearerPrefix = "Bearer "
+	apiKeyHeader = "X-API-Key"
+)
+
+// authMiddleware аутентифицирует вызывающего по API ключу из X-API-Key или по Bearer JWT
+// и сохраняет его в контексте запроса. Пробы оркестратора и документация API доступны без аутентификации.
+func authMiddleware(verifier *auth.JWTVerifier, apiKeys interactors.ApiKeysInteractor, log logger.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if path := ctx.Path(); path == livenessPath || path == readinessPath || path == openAPIPath || path == docsPath || path == docsAssetsPath {
+				return next(ctx)
+			}
+
+			req := ctx.Request()
+			var actor *auth.Actor
+			if key := req.Header.Get(apiKeyHeader); key != "" && apiKeys != nil {
+				var err error
+				actor, err = apiKeys.Authenticate(req.Context(), key)
+				if errors.Is(err, interactors.ErrInvalidApiKey) {
+					return unauthorized(ctx)
+				}
+				if err != nil {
+					return fmt.Errorf("error authenticating api key: %w", err)
+				}
+			} else {
+				token, ok := strings.CutPrefix(req.Header.Get(echo.HeaderAuthorization), bearerPrefix)
+				if !ok || token == "" {
+					return unauthorized(ctx)
+				}
+
+				var err error
+				actor, err = verifier.Verify(token)
+				if err != nil {
+					return unauthorized(ctx)
+				}
+			}
+
+			ctx.SetRequest(req.WithContext(auth.ContextWithActor(req.Context(), actor)))
+
+			return next(ctx)
+		}
+	}
+}
+
+func unauthorized(ctx echo.Context) error {
+	ctx.Response().Header().Set(echo.HeaderWWWAuthenticate, "Bearer")
+	return apperrors.Unauthorized
+}
+
+// authorizeProject проверяет, что у вызывающего есть роль не ниже required в проекте.
+// Без вызывающего в контексте (аутентификация отключена) доступ не ограничивается.
+// projectId 0 означает все проекты и разрешен только с ролью для "*".
+func authorizeProject(ctx echo.Context, projectId int, required auth.Role) error {
+	actor, ok := auth.ActorFromContext(ctx.Request().Context())
+	if !ok || actor.Allows(projectId, required) {
+		return nil
+	}
+
+	return apperrors.Forbidden
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"net/http"
+	"rest_clickhouse/internal/api"
+	"rest_clickhouse/internal/infrastructure/usecase/interactors"
+	"rest_clickhouse/pkg/auth"
+	"rest_clickhouse/pkg/logger"
+
+	"github.com/labstack/echo/v4"
+)
+
+type CategoriesService interface {
+	HandleCreateCategory(ctx echo.Context) error
+	HandleGetCategories(ctx echo.Context) error
+	HandleRemoveCategory(ctx echo.Context) error
+}
+
+type categoriesService struct {
+	categoriesInteractor interactors.CategoriesInteractor
+	logger               logger.Logger
+}
+
+func NewCategoriesService(categoriesInteractor interactors.CategoriesInteractor, logger logger.Logger) CategoriesService {
+	return &categoriesService{
+		categoriesInteractor: categoriesInteractor,
+		logger:               logger,
+	}
+}
+
+func (c *categoriesService) HandleCreateCategory(ctx echo.Context) error {
+	category := new(api.Category)
+	projectId, err := pathParamInt(ctx, "projectId")
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeProject(ctx, projectId, auth.RoleEditor); err != nil {
+		return err
+	}
+
+	if err := bindBody(ctx, category); err != nil {
+		return err
+	}
+
+	category.ProjectId = projectId
+
+	categoryDTO, err := c.categoriesInteractor.CreateCategory(ctx.Request().Context(), category)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusCreated, api.GetCategory(categoryDTO))
+}
+
+func (c *categoriesService) HandleGetCategories(ctx echo.Context) error {
+	projectId, err := pathParamInt(ctx, "projectId")
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeProject(ctx, projectId, auth.RoleViewer); err != nil {
+		return err
+	}
+
+	categoryModels, err := c.categoriesInteractor.GetList(ctx.Request().Context(), projectId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, api.GetCategoryTree(categoryModels))
+}
+
+func (c *categoriesService) HandleRemoveCategory(ctx echo.Context) error {
+	id, err := pathParamInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	projectId, err := pathParamInt(ctx, "projectId")
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeProject(ctx, projectId, auth.RoleEditor); err != nil {
+		return err
+	}
+
+	err = c.categoriesInteractor.RemoveCategory(ctx.Request().Context(), id, projectId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
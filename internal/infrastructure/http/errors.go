@@ -0,0 +1,156 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"rest_clickhouse/internal/api"
+	"rest_clickhouse/internal/apperrors"
+	"rest_clickhouse/pkg/logger"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// httpStatuses сопоставляет виды ошибок предметной области статусам ответа.
+var httpStatuses = map[apperrors.Kind]int{
+	apperrors.KindInternal:             http.StatusInternalServerError,
+	apperrors.KindNotFound:             http.StatusNotFound,
+	apperrors.KindConflict:             http.StatusConflict,
+	apperrors.KindValidation:           http.StatusBadRequest,
+	apperrors.KindUnavailable:          http.StatusServiceUnavailable,
+	apperrors.KindUnauthorized:         http.StatusUnauthorized,
+	apperrors.KindForbidden:            http.StatusForbidden,
+	apperrors.KindTooManyRequests:      http.StatusTooManyRequests,
+	apperrors.KindNotAcceptable:        http.StatusNotAcceptable,
+	apperrors.KindUnsupportedMediaType: http.StatusUnsupportedMediaType,
+}
+
+// errorHandler отвечает на ошибку обработчика или middleware в формате api.ErrorResponse.
+// Статус выбирается по виду ошибки предметной области, остальные ошибки логируются и отдаются как внутренние.
+func errorHandler(log logger.Logger) echo.HTTPErrorHandler {
+	return func(err error, ctx echo.Context) {
+		if ctx.Response().Committed {
+			return
+		}
+
+		// Ошибки предметной области ожидаемы, логируются только непредвиденные.
+		status, domainErr := resolveError(err)
+		if status >= http.StatusInternalServerError && !errors.Is(err, domainErr) {
+			log.WithContext(ctx.Request().Context()).ErrorF("error handling %s %s: %v", ctx.Request().Method, ctx.Path(), err)
+		}
+
+		if ctx.Request().Method == http.MethodHead {
+			err = ctx.NoContent(status)
+		} else {
+			err = ctx.JSON(status, api.NewErrorResponse(domainErr.Code, domainErr.Message, domainErr.Details...))
+		}
+		if err != nil {
+			log.WithContext(ctx.Request().Context()).ErrorF("error writing error response: %v", err)
+		}
+	}
+}
+
+// resolveError находит ошибку предметной области в цепочке err. Ошибки Echo (нет маршрута, лимит тела)
+// сохраняют свой статус.
+func resolveError(err error) (int, *apperrors.Error) {
+	var domainErr *apperrors.Error
+	if errors.As(err, &domainErr) {
+		return httpStatuses[domainErr.Kind], domainErr
+	}
+
+	var httpErr *echo.HTTPError
+	if !errors.As(err, &httpErr) {
+		return http.StatusInternalServerError, apperrors.Internal
+	}
+
+	switch {
+	case httpErr.Code == http.StatusNotFound || httpErr.Code == http.StatusMethodNotAllowed:
+		return httpErr.Code, apperrors.RouteNotFound
+	case httpErr.Code == http.StatusUnauthorized:
+		return httpErr.Code, apperrors.Unauthorized
+	case httpErr.Code >= http.StatusInternalServerError:
+		return http.StatusInternalServerError, apperrors.Internal
+	default:
+		return httpErr.Code, apperrors.InvalidRequest.WithDetails(fmt.Sprint(httpErr.Message))
+	}
+}
+
+// pathParamInt возвращает числовой параметр пути.
+func pathParamInt(ctx echo.Context, name string) (int, error) {
+	value, err := strconv.Atoi(ctx.Param(name))
+	if err != nil {
+		return 0, apperrors.InvalidRequest.WithField(name, "must be an integer")
+	}
+
+	return value, nil
+}
+
+// paramInt возвращает числовой параметр пути, а если в маршруте его нет - query-параметр.
+// Так один обработчик обслуживает маршруты v1 и v2, в которых параметр передается по-разному.
+func paramInt(ctx echo.Context, name string) (int, error) {
+	for _, param := range ctx.ParamNames() {
+		if param == name {
+			return pathParamInt(ctx, name)
+		}
+	}
+
+	return queryParamInt(ctx, name)
+}
+
+// queryParamInt возвращает числовой query-параметр, отсутствующий параметр равен нулю.
+func queryParamInt(ctx echo.Context, name string) (int, error) {
+	value := ctx.QueryParam(name)
+	if value == "" {
+		return 0, nil
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, apperrors.InvalidRequest.WithField(name, "must be an integer")
+	}
+
+	return number, nil
+}
+
+// queryParamTime разбирает время в RFC 3339, отсутствующий параметр - нулевое время.
+func queryParamTime(ctx echo.Context, name string) (time.Time, error) {
+	value := ctx.QueryParam(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, apperrors.InvalidRequest.WithField(name, "must be an RFC 3339 time")
+	}
+
+	return parsed, nil
+}
+
+// bindBody разбирает тело запроса в dst и проверяет его правилами api.Validate.
+func bindBody(ctx echo.Context, dst interface{}) error {
+	if err := ctx.Bind(dst); err != nil {
+		return apperrors.InvalidRequest.WithField("body", "must be a valid JSON object")
+	}
+
+	return api.Validate(dst)
+}
+
+// bindMergePatch разбирает тело, переданное как application/merge-patch+json или application/json.
+// Binder Echo принимает только application/json, поэтому merge patch декодируется напрямую.
+func bindMergePatch(ctx echo.Context, dst interface{}) error {
+	mediaType, _, _ := mime.ParseMediaType(ctx.Request().Header.Get(echo.HeaderContentType))
+	if mediaType != mergePatchMIME {
+		return bindBody(ctx, dst)
+	}
+
+	if err := json.NewDecoder(ctx.Request().Body).Decode(dst); err != nil {
+		return apperrors.InvalidRequest.WithField("body", "must be a valid JSON object")
+	}
+
+	return api.Validate(dst)
+}
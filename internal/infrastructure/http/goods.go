@@ -1,14 +1,13 @@
 package http
 
 import (
-	"errors"
-	"fmt"
 	"net/http"
 	"rest_clickhouse/internal/api"
-	repository2 "rest_clickhouse/internal/infrastructure/repository"
+	"rest_clickhouse/internal/apperrors"
 	"rest_clickhouse/internal/infrastructure/usecase/interactors"
+	"rest_clickhouse/pkg/auth"
 	"rest_clickhouse/pkg/logger"
-	"strconv"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 )
@@ -16,10 +15,18 @@ import (
 type GoodsService interface {
 	HandleCreateGood(c echo.Context) error
 	HandleGetGood(ctx echo.Context) error
+	HandleGetGoodById(ctx echo.Context) error
 	HandleRemoveGood(ctx echo.Context) error
 	HandleUpdateGoods(ctx echo.Context) error
+	HandleReplaceGood(ctx echo.Context) error
+	HandleSearchGoods(ctx echo.Context) error
+	HandleSetGoodTags(ctx echo.Context) error
+	HandleSetGoodCategory(ctx echo.Context) error
+	HandleGetTags(ctx echo.Context) error
 }
 
+const attributeQueryPrefix = "attr."
+
 type goodsService struct {
 	goodsInteractor interactors.GoodsInteractor
 	logger          logger.Logger
@@ -34,26 +41,24 @@ func NewGoodsService(goodsInteractor interactors.GoodsInteractor, logger logger.
 
 func (c *goodsService) HandleCreateGood(ctx echo.Context) error {
 	good := new(api.Good)
-	projectId, err := strconv.Atoi(ctx.Param("projectId"))
+	projectId, err := pathParamInt(ctx, "projectId")
 	if err != nil {
-		return ctx.String(http.StatusBadRequest, "invalid url params")
+		return err
 	}
 
-	err = ctx.Bind(&good)
-	if err != nil {
-		return ctx.String(http.StatusBadRequest, "invalid body")
+	if err := authorizeProject(ctx, projectId, auth.RoleEditor); err != nil {
+		return err
 	}
 
-	good.ProjectId = projectId
-
-	goodDTO, err := c.goodsInteractor.CreateGood(good)
-	if errors.Is(err, repository2.ErrProjectNotExist) {
-		return ctx.String(http.StatusNotFound, "ProjectId not found")
+	if err := bindBody(ctx, good); err != nil {
+		return err
 	}
 
+	good.ProjectId = projectId
+
+	goodDTO, err := c.goodsInteractor.CreateGood(ctx.Request().Context(), good)
 	if err != nil {
-		fmt.Printf("error on create good: %w", err)
-		return ctx.String(http.StatusInternalServerError, "internal error")
+		return err
 	}
 
 	response := api.GetUpdatedGood(goodDTO)
@@ -61,19 +66,40 @@ func (c *goodsService) HandleCreateGood(ctx echo.Context) error {
 }
 
 func (c *goodsService) HandleGetGood(ctx echo.Context) error {
-	limit, err := strconv.Atoi(ctx.Param("limit"))
+	limit, err := paramInt(ctx, "limit")
+	if err != nil {
+		return err
+	}
+
+	offset, err := paramInt(ctx, "offset")
 	if err != nil {
-		return ctx.String(http.StatusBadRequest, "Invalid limit")
+		return err
 	}
 
-	offset, err := strconv.Atoi(ctx.Param("offset"))
+	categoryId, err := queryParamInt(ctx, "categoryId")
 	if err != nil {
-		return ctx.String(http.StatusBadRequest, "Invalid offset")
+		return err
+	}
+
+	projectId, err := paramInt(ctx, "projectId")
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeProject(ctx, projectId, auth.RoleViewer); err != nil {
+		return err
 	}
 
-	goodsModelList, err := c.goodsInteractor.GetList(limit, offset)
+	filter := api.GoodListFilter{
+		ProjectId:  projectId,
+		Tag:        strings.ToLower(strings.TrimSpace(ctx.QueryParam("tag"))),
+		CategoryId: categoryId,
+		Attributes: attributesQueryParams(ctx),
+	}
+
+	goodsModelList, err := c.goodsInteractor.GetList(ctx.Request().Context(), limit, offset, filter)
 	if err != nil {
-		return ctx.String(http.StatusInternalServerError, "internal error")
+		return err
 	}
 
 	goodsList := api.GetGoodList(*goodsModelList)
@@ -81,29 +107,52 @@ func (c *goodsService) HandleGetGood(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, goodsList)
 }
 
+func (c *goodsService) HandleGetGoodById(ctx echo.Context) error {
+	id, err := pathParamInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	projectId, err := pathParamInt(ctx, "projectId")
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeProject(ctx, projectId, auth.RoleViewer); err != nil {
+		return err
+	}
+
+	goodDTO, err := c.goodsInteractor.GetGood(ctx.Request().Context(), id, projectId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, api.GetGood(goodDTO))
+}
+
 func (c *goodsService) HandleRemoveGood(ctx echo.Context) error {
 	good := new(api.Good)
 
-	id, err := strconv.Atoi(ctx.Param("id"))
+	id, err := pathParamInt(ctx, "id")
 	if err != nil {
-		return ctx.String(http.StatusBadRequest, "invalid url params")
+		return err
 	}
 
-	projectId, err := strconv.Atoi(ctx.Param("projectId"))
+	projectId, err := pathParamInt(ctx, "projectId")
 	if err != nil {
-		return ctx.String(http.StatusBadRequest, "invalid url params")
+		return err
+	}
+
+	if err := authorizeProject(ctx, projectId, auth.RoleEditor); err != nil {
+		return err
 	}
 
 	good.Id = id
 	good.ProjectId = projectId
 
-	goodDTO, err := c.goodsInteractor.RemoveGood(good)
-	if errors.Is(err, repository2.ErrGoodNotExist) {
-		return ctx.JSON(http.StatusNotFound, api.NewErrorResponse(api.GoodNotFoundCode, api.GoodNotFoundMessage))
-	}
-
+	goodDTO, err := c.goodsInteractor.RemoveGood(ctx.Request().Context(), good)
 	if err != nil {
-		return ctx.String(http.StatusInternalServerError, "internal error")
+		return err
 	}
 
 	response := api.GetRemovedGood(goodDTO)
@@ -111,39 +160,180 @@ func (c *goodsService) HandleRemoveGood(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, response)
 }
 
+// HandleUpdateGoods применяет к товару JSON Merge Patch.
 func (c *goodsService) HandleUpdateGoods(ctx echo.Context) error {
-	good := new(api.Good)
-	id, err := strconv.Atoi(ctx.Param("id"))
+	patch, err := goodPatch(ctx)
 	if err != nil {
-		return ctx.String(http.StatusBadRequest, "invalid url params")
+		return err
 	}
 
-	projectId, err := strconv.Atoi(ctx.Param("projectId"))
+	goodDTO, err := c.goodsInteractor.PatchGood(ctx.Request().Context(), patch)
 	if err != nil {
-		return ctx.String(http.StatusBadRequest, "invalid url params")
+		return err
 	}
 
-	err = ctx.Bind(&good)
+	return ctx.JSON(http.StatusOK, api.GetGood(goodDTO))
+}
+
+// HandleReplaceGood заменяет товар целиком.
+func (c *goodsService) HandleReplaceGood(ctx echo.Context) error {
+	patch, err := goodPatch(ctx)
 	if err != nil {
-		return ctx.String(http.StatusBadRequest, "invalid body")
+		return err
 	}
 
-	if good.Name == "" {
-		return ctx.String(http.StatusBadRequest, "invalid name")
+	goodDTO, err := c.goodsInteractor.ReplaceGood(ctx.Request().Context(), patch)
+	if err != nil {
+		return err
 	}
 
-	good.Id = id
-	good.ProjectId = projectId
+	return ctx.JSON(http.StatusOK, api.GetGood(goodDTO))
+}
+
+// goodPatch проверяет права на проект и разбирает тело PATCH или PUT товара.
+func goodPatch(ctx echo.Context) (*api.GoodPatch, error) {
+	id, err := pathParamInt(ctx, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	projectId, err := pathParamInt(ctx, "projectId")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := authorizeProject(ctx, projectId, auth.RoleEditor); err != nil {
+		return nil, err
+	}
+
+	patch := new(api.GoodPatch)
+	if err := bindMergePatch(ctx, patch); err != nil {
+		return nil, err
+	}
+
+	patch.Id = id
+	patch.ProjectId = projectId
+
+	return patch, nil
+}
+
+func (c *goodsService) HandleSearchGoods(ctx echo.Context) error {
+	query := strings.TrimSpace(ctx.QueryParam("q"))
+	if query == "" {
+		return apperrors.InvalidRequest.WithField("q", "required")
+	}
 
-	goodDTO, err := c.goodsInteractor.UpdateGood(good)
-	if errors.Is(err, repository2.ErrGoodNotExist) {
-		return ctx.JSON(http.StatusNotFound, api.NewErrorResponse(api.GoodNotFoundCode, api.GoodNotFoundMessage))
+	projectId, err := paramInt(ctx, "projectId")
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeProject(ctx, projectId, auth.RoleViewer); err != nil {
+		return err
+	}
+
+	limit, err := queryParamInt(ctx, "limit")
+	if err != nil {
+		return err
+	}
+
+	offset, err := queryParamInt(ctx, "offset")
+	if err != nil {
+		return err
+	}
+
+	goodsSearchList, err := c.goodsInteractor.SearchGoods(ctx.Request().Context(), query, projectId, limit, offset)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, api.GetGoodSearchList(*goodsSearchList))
+}
+
+func (c *goodsService) HandleSetGoodTags(ctx echo.Context) error {
+	goodTags := new(api.GoodTags)
+	id, err := pathParamInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	projectId, err := pathParamInt(ctx, "projectId")
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeProject(ctx, projectId, auth.RoleEditor); err != nil {
+		return err
+	}
+
+	if err := bindBody(ctx, goodTags); err != nil {
+		return err
 	}
 
+	goodDTO, err := c.goodsInteractor.SetGoodTags(ctx.Request().Context(), &api.Good{Id: id, ProjectId: projectId, Tags: goodTags.Tags})
 	if err != nil {
-		return ctx.String(http.StatusInternalServerError, "internal error")
+		return err
 	}
 
 	response := api.GetUpdatedGood(goodDTO)
 	return ctx.JSON(http.StatusOK, response)
 }
+
+func (c *goodsService) HandleSetGoodCategory(ctx echo.Context) error {
+	goodCategory := new(api.GoodCategory)
+	id, err := pathParamInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	projectId, err := pathParamInt(ctx, "projectId")
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeProject(ctx, projectId, auth.RoleEditor); err != nil {
+		return err
+	}
+
+	if err := bindBody(ctx, goodCategory); err != nil {
+		return err
+	}
+
+	goodDTO, err := c.goodsInteractor.SetGoodCategory(ctx.Request().Context(), &api.Good{Id: id, ProjectId: projectId, CategoryId: goodCategory.CategoryId})
+	if err != nil {
+		return err
+	}
+
+	response := api.GetUpdatedGood(goodDTO)
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (c *goodsService) HandleGetTags(ctx echo.Context) error {
+	projectId, err := pathParamInt(ctx, "projectId")
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeProject(ctx, projectId, auth.RoleViewer); err != nil {
+		return err
+	}
+
+	tagModels, err := c.goodsInteractor.GetTags(ctx.Request().Context(), projectId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, api.GetTagList(tagModels))
+}
+
+// attributesQueryParams собирает фильтры по атрибутам вида attr.color=red.
+func attributesQueryParams(ctx echo.Context) map[string]string {
+	attributes := make(map[string]string)
+	for name, values := range ctx.QueryParams() {
+		if attribute, ok := strings.CutPrefix(name, attributeQueryPrefix); ok && attribute != "" && len(values) > 0 {
+			attributes[attribute] = values[0]
+		}
+	}
+
+	return attributes
+}
@@ -0,0 +1,138 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"rest_clickhouse/internal/api"
+	"rest_clickhouse/internal/apperrors"
+	nats_client "rest_clickhouse/internal/infrastructure/queue/nats"
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	"rest_clickhouse/pkg/auth"
+	"rest_clickhouse/pkg/features"
+	"rest_clickhouse/pkg/logger"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	streamHeartbeatInterval = 15 * time.Second
+	streamRetryMillis       = 3000
+)
+
+type GoodsStreamService interface {
+	HandleStreamGoods(ctx echo.Context) error
+}
+
+type goodsStreamService struct {
+	broadcaster *nats_client.EventBroadcaster
+	features    *features.Flags
+	logger      logger.Logger
+}
+
+func NewGoodsStreamService(broadcaster *nats_client.EventBroadcaster, features *features.Flags, logger logger.Logger) GoodsStreamService {
+	return &goodsStreamService{
+		broadcaster: broadcaster,
+		features:    features,
+		logger:      logger,
+	}
+}
+
+// HandleStreamGoods отправляет изменения товаров как Server-Sent Events.
+// Заголовок Last-Event-ID позволяет получить пропущенные события из буфера. Если они уже вытеснены,
+// поток начинается с события reset, после которого клиенту нужно перечитать товары.
+func (c *goodsStreamService) HandleStreamGoods(ctx echo.Context) error {
+	if !c.features.GoodsStream() {
+		return apperrors.FeatureDisabled.WithDetails("goods stream")
+	}
+
+	projectId, err := paramInt(ctx, "projectId")
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeProject(ctx, projectId, auth.RoleViewer); err != nil {
+		return err
+	}
+
+	var lastEventId uint64
+	if header := ctx.Request().Header.Get("Last-Event-ID"); header != "" {
+		lastEventId, err = strconv.ParseUint(header, 10, 64)
+		if err != nil {
+			return apperrors.InvalidRequest.WithField("Last-Event-ID", "must be an unsigned integer")
+		}
+	}
+
+	backlog, events, unsubscribe := c.broadcaster.Subscribe(lastEventId)
+	defer unsubscribe()
+
+	res := ctx.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(res, "retry: %d\n\n", streamRetryMillis); err != nil {
+		return nil
+	}
+
+	for _, event := range backlog {
+		if err := c.writeEvent(res, event, projectId); err != nil {
+			return nil
+		}
+	}
+	res.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Request().Context().Done():
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if err := c.writeEvent(res, event, projectId); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
+
+// writeEvent отправляет событие с типом изменения (good.created, good.updated, good.removed) в поле event.
+// Событие reset отправляется всем подписчикам: его номер продолжает поток, а данные пусты.
+func (c *goodsStreamService) writeEvent(res *echo.Response, event nats_client.BroadcastEvent, projectId int) error {
+	if event.Type == nats_client.ResetEvent {
+		_, err := fmt.Fprintf(res, "id: %d\nevent: %s\ndata: {}\n\n", event.Id, event.Type)
+		return err
+	}
+
+	if projectId != 0 && event.Good.ProjectId != projectId {
+		return nil
+	}
+
+	eventType := event.Type
+	if eventType == "" {
+		eventType = repository.GoodUpdatedEvent
+	}
+
+	data, err := json.Marshal(api.GetGoodChange(event.Good))
+	if err != nil {
+		c.logger.ErrorF("error marshaling stream event: %v", err)
+		return nil
+	}
+
+	_, err = fmt.Fprintf(res, "id: %d\nevent: %s\ndata: %s\n\n", event.Id, eventType, data)
+	return err
+}
@@ -0,0 +1,107 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"rest_clickhouse/internal/api"
+	"rest_clickhouse/internal/infrastructure/graphql"
+	"rest_clickhouse/pkg/logger"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const graphQLPath = "/graphql"
+
+type GraphQLService interface {
+	HandleGraphQL(ctx echo.Context) error
+}
+
+type graphQLService struct {
+	server *graphql.Server
+	logger logger.Logger
+}
+
+func NewGraphQLService(server *graphql.Server, logger logger.Logger) GraphQLService {
+	return &graphQLService{
+		server: server,
+		logger: logger,
+	}
+}
+
+// HandleGraphQL исполняет запрос GraphQL и отвечает JSON. С Accept: text/event-stream ответы операции,
+// в том числе каждое событие подписки, приходят событиями next, поток завершает событие complete.
+func (c *graphQLService) HandleGraphQL(ctx echo.Context) error {
+	var request api.GraphQLRequest
+	if err := bindBody(ctx, &request); err != nil {
+		return err
+	}
+
+	if !prefersEventStream(ctx.Request().Header.Get(echo.HeaderAccept)) {
+		return ctx.JSON(http.StatusOK, c.server.Exec(ctx.Request().Context(), request))
+	}
+
+	responses, err := c.server.Subscribe(ctx.Request().Context(), request)
+	if err != nil {
+		return err
+	}
+
+	res := ctx.Response()
+	res.Header().Set(echo.HeaderContentType, eventStreamMIME)
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Request().Context().Done():
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(res, ": heartbeat\n\n"); err != nil {
+				return nil
+			}
+			res.Flush()
+		case response, ok := <-responses:
+			if !ok {
+				fmt.Fprint(res, "event: complete\ndata:\n\n")
+				res.Flush()
+				return nil
+			}
+
+			data, err := json.Marshal(response)
+			if err != nil {
+				c.logger.ErrorF("error marshaling graphql response: %v", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(res, "event: next\ndata: %s\n\n", data); err != nil {
+				return nil
+			}
+			res.Flush()
+		}
+	}
+}
+
+// prefersEventStream проверяет, что клиент явно запросил text/event-stream.
+func prefersEventStream(accept string) bool {
+	for _, item := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(item))
+		if err != nil || mediaType != eventStreamMIME {
+			continue
+		}
+		if weight, err := strconv.ParseFloat(params["q"], 64); err == nil && weight == 0 {
+			continue
+		}
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,45 @@
+package http
+
+import (
+	"net/http"
+	"rest_clickhouse/pkg/health"
+	"rest_clickhouse/pkg/logger"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	livenessPath  = "/healthz"
+	readinessPath = "/readyz"
+)
+
+type HealthService interface {
+	HandleLiveness(ctx echo.Context) error
+	HandleReadiness(ctx echo.Context) error
+}
+
+type healthService struct {
+	checker *health.Checker
+	logger  logger.Logger
+}
+
+func NewHealthService(checker *health.Checker, logger logger.Logger) HealthService {
+	return &healthService{
+		checker: checker,
+		logger:  logger,
+	}
+}
+
+// HandleLiveness отвечает, пока процесс способен обрабатывать запросы, и не проверяет зависимости.
+func (c *healthService) HandleLiveness(ctx echo.Context) error {
+	return ctx.JSON(http.StatusOK, map[string]string{"status": health.StatusUp})
+}
+
+func (c *healthService) HandleReadiness(ctx echo.Context) error {
+	report := c.checker.Check(ctx.Request().Context())
+	if report.Status != health.StatusUp {
+		return ctx.JSON(http.StatusServiceUnavailable, report)
+	}
+
+	return ctx.JSON(http.StatusOK, report)
+}
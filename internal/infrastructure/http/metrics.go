@@ -0,0 +1,81 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"rest_clickhouse/pkg/logger"
+	"rest_clickhouse/pkg/metrics"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+type MetricsHTTPServer struct {
+	server *http.Server
+	logger logger.Logger
+}
+
+func NewMetricsHTTPServer(ServerPort string, logger logger.Logger) *MetricsHTTPServer {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	return &MetricsHTTPServer{
+		server: &http.Server{
+			Addr:              fmt.Sprintf(":%v", ServerPort),
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+		logger: logger,
+	}
+}
+
+// Start занимает порт сразу, чтобы ошибка прослушивания остановила запуск приложения, и обслуживает запросы в фоне.
+func (s *MetricsHTTPServer) Start() error {
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return fmt.Errorf("metrics listen error: %w", err)
+	}
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("Metrics server error:", err)
+		}
+	}()
+
+	return nil
+}
+
+func (s *MetricsHTTPServer) Stop(ctx context.Context) {
+	if err := s.server.Shutdown(ctx); err != nil {
+		s.logger.Error("Metrics server error:", err)
+	}
+}
+
+// metricsMiddleware считает запросы и время их обработки по шаблону маршрута.
+func metricsMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		start := time.Now()
+		err := next(ctx)
+
+		status := ctx.Response().Status
+		if err != nil {
+			status, _ = resolveError(err)
+		}
+
+		route := ctx.Path()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		labels := []string{ctx.Request().Method, route, strconv.Itoa(status)}
+		metrics.HTTPRequestsTotal.WithLabelValues(labels...).Inc()
+		metrics.HTTPRequestDuration.WithLabelValues(labels...).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
@@ -0,0 +1,166 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"rest_clickhouse/internal/apperrors"
+	"rest_clickhouse/internal/infrastructure/usecase/interactors"
+	"rest_clickhouse/pkg/audit"
+	"rest_clickhouse/pkg/auth"
+	"rest_clickhouse/pkg/logger"
+	"rest_clickhouse/pkg/ratelimit"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
+)
+
+// MiddlewareConfig настраивает общие middleware Echo сервера.
+type MiddlewareConfig struct {
+	// RequestIdHeader - заголовок, из которого берется и в который возвращается id запроса.
+	RequestIdHeader string
+	AccessLog       bool
+	// BodyLimit - максимальный размер тела запроса, например "1M". Пустое значение отключает проверку.
+	BodyLimit string
+	// RecoverStack добавляет стек горутины в лог перехваченной паники.
+	RecoverStack bool
+	// Auth проверяет токены вызывающих, nil отключает аутентификацию.
+	Auth *auth.JWTVerifier
+	// ApiKeys проверяет ключи из заголовка X-API-Key, действует только вместе с Auth.
+	ApiKeys interactors.ApiKeysInteractor
+	// RateLimiter и RateLimits ограничивают частоту запросов, nil отключает ограничение.
+	RateLimiter ratelimit.Limiter
+	RateLimits  *ratelimit.Settings
+}
+
+func (s *EchoHTTPServer) useMiddlewares() {
+	s.echo.Use(requestIdMiddleware(s.middlewareConfig.RequestIdHeader))
+	s.echo.Use(projectContextMiddleware)
+	s.echo.Use(clientContextMiddleware)
+	if s.middlewareConfig.AccessLog {
+		s.echo.Use(accessLogMiddleware(s.logger))
+	}
+	s.echo.Use(otelecho.Middleware(tracingServiceName))
+	s.echo.Use(metricsMiddleware)
+	s.echo.Use(recoverMiddleware(s.logger, s.middlewareConfig.RecoverStack))
+	if s.middlewareConfig.BodyLimit != "" {
+		s.echo.Use(middleware.BodyLimit(s.middlewareConfig.BodyLimit))
+	}
+	if s.middlewareConfig.Auth != nil {
+		s.echo.Use(authMiddleware(s.middlewareConfig.Auth, s.middlewareConfig.ApiKeys, s.logger))
+	}
+	// Лимиты проверяются после аутентификации, чтобы считать запросы по вызывающему, а не по IP.
+	if s.middlewareConfig.RateLimiter != nil && s.middlewareConfig.RateLimits != nil {
+		s.echo.Use(rateLimitMiddleware(s.middlewareConfig.RateLimiter, s.middlewareConfig.RateLimits, s.logger))
+	}
+}
+
+// requestIdMiddleware принимает id запроса от клиента или генерирует новый и сохраняет его в контексте для логгера.
+func requestIdMiddleware(header string) echo.MiddlewareFunc {
+	return middleware.RequestIDWithConfig(middleware.RequestIDConfig{
+		TargetHeader: header,
+		RequestIDHandler: func(ctx echo.Context, requestId string) {
+			req := ctx.Request()
+			ctx.SetRequest(req.WithContext(logger.ContextWithRequestId(req.Context(), requestId)))
+		},
+	})
+}
+
+// accessLogMiddleware пишет по записи на каждый запрос после его обработки.
+// Пробы оркестратора не логируются, чтобы не забивать лог.
+func accessLogMiddleware(log logger.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			if path := ctx.Path(); path == livenessPath || path == readinessPath {
+				return next(ctx)
+			}
+
+			start := time.Now()
+			err := next(ctx)
+			if err != nil {
+				// Отдаем ошибку обработчику Echo сейчас, чтобы в логе был итоговый статус.
+				ctx.Error(err)
+			}
+
+			req := ctx.Request()
+			res := ctx.Response()
+			log.WithContext(req.Context()).With(
+				"method", req.Method,
+				"route", ctx.Path(),
+				"uri", req.RequestURI,
+				"status", res.Status,
+				"latency_ms", time.Since(start).Milliseconds(),
+				"bytes_in", req.ContentLength,
+				"bytes_out", res.Size,
+				"remote_ip", ctx.RealIP(),
+				"user_agent", req.UserAgent(),
+			).Info("request")
+
+			return nil
+		}
+	}
+}
+
+// recoverMiddleware перехватывает панику обработчика и отвечает 500 в формате api.ErrorResponse.
+func recoverMiddleware(log logger.Logger, withStack bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) (err error) {
+			defer func() {
+				recovered := recover()
+				if recovered == nil {
+					return
+				}
+				if recovered == http.ErrAbortHandler {
+					panic(recovered)
+				}
+
+				panicLog := log.WithContext(ctx.Request().Context())
+				if withStack {
+					panicLog = panicLog.With("stack", string(debug.Stack()))
+				}
+				panicLog.ErrorF("panic recovered: %v", recovered)
+
+				if ctx.Response().Committed {
+					err = fmt.Errorf("panic after response committed: %v", recovered)
+					return
+				}
+				err = apperrors.Internal
+			}()
+
+			return next(ctx)
+		}
+	}
+}
+
+// projectContextMiddleware сохраняет projectId из пути или query в контексте запроса для логгера.
+func projectContextMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		projectParam := ctx.Param("projectId")
+		if projectParam == "" {
+			projectParam = ctx.QueryParam("projectId")
+		}
+
+		if projectId, err := strconv.Atoi(projectParam); err == nil {
+			req := ctx.Request()
+			ctx.SetRequest(req.WithContext(logger.ContextWithProjectId(req.Context(), projectId)))
+		}
+
+		return next(ctx)
+	}
+}
+
+// clientContextMiddleware сохраняет адрес и User-Agent вызывающего для журнала изменений.
+func clientContextMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		req := ctx.Request()
+		ctx.SetRequest(req.WithContext(audit.ContextWithClient(req.Context(), audit.Client{
+			IP:        ctx.RealIP(),
+			UserAgent: req.UserAgent(),
+		})))
+
+		return next(ctx)
+	}
+}
@@ -0,0 +1,174 @@
+package http
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"rest_clickhouse/internal/api"
+	"rest_clickhouse/internal/apperrors"
+	"rest_clickhouse/pkg/health"
+	"sort"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	openAPIPath    = "/openapi.json"
+	docsPath       = "/docs"
+	docsAssetsPath = "/docs/:file"
+	docsAssetsDir  = "openapi/swagger-ui/"
+)
+
+// Статика Swagger UI (swagger-ui-dist) встроена в бинарник, чтобы документация не зависела от CDN.
+//
+//go:embed openapi/openapi.json openapi/index.html openapi/swagger-ui
+var openAPIFiles embed.FS
+
+// docsAssetTypes - файлы swagger-ui-dist, которые отдает сервер, и их типы.
+var docsAssetTypes = map[string]string{
+	"swagger-ui.css":       "text/css; charset=utf-8",
+	"swagger-ui-bundle.js": "text/javascript; charset=utf-8",
+}
+
+// openAPISchemas - DTO, схемы которых подставляются в components.schemas спецификации.
+var openAPISchemas = map[string]interface{}{
+	"Good":                api.Good{},
+	"GoodPatch":           api.GoodPatch{},
+	"GoodChange":          api.GoodChange{},
+	"GoodList":            api.GoodList{},
+	"GoodSearchResult":    api.GoodSearchResult{},
+	"GoodSearchList":      api.GoodSearchList{},
+	"Highlight":           api.Highlight{},
+	"Meta":                api.Meta{},
+	"GoodTags":            api.GoodTags{},
+	"GoodCategory":        api.GoodCategory{},
+	"Tag":                 api.Tag{},
+	"TagList":             api.TagList{},
+	"Category":            api.Category{},
+	"CategoryList":        api.CategoryList{},
+	"Attribute":           api.Attribute{},
+	"AttributeList":       api.AttributeList{},
+	"Webhook":             api.Webhook{},
+	"WebhookList":         api.WebhookList{},
+	"WebhookDelivery":     api.WebhookDelivery{},
+	"WebhookDeliveryList": api.WebhookDeliveryList{},
+	"ApiKey":              api.ApiKey{},
+	"ApiKeyList":          api.ApiKeyList{},
+	"AuditRecord":         api.AuditRecord{},
+	"AuditList":           api.AuditList{},
+	"GraphQLRequest":      api.GraphQLRequest{},
+	"GraphQLResponse":     api.GraphQLResponse{},
+	"GraphQLError":        api.GraphQLError{},
+	"ErrorResponse":       api.ErrorResponse{},
+	"HealthReport":        health.Report{},
+	"HealthCheck":         health.CheckResult{},
+}
+
+var (
+	routeParamRegexp = regexp.MustCompile(`:([A-Za-z0-9_]+)`)
+	schemaRefRegexp  = regexp.MustCompile(`"#/components/schemas/([^"]+)"`)
+)
+
+// checkOpenAPISpec сверяет встроенную спецификацию с маршрутами сервера: каждый маршрут должен быть описан,
+// каждая операция - зарегистрирована, каждая ссылка на схему - разрешаться.
+func checkOpenAPISpec(routes []*echo.Route) error {
+	data, err := openAPIFiles.ReadFile("openapi/openapi.json")
+	if err != nil {
+		return fmt.Errorf("error reading openapi spec: %w", err)
+	}
+
+	var spec struct {
+		Paths map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return fmt.Errorf("error parsing openapi spec: %w", err)
+	}
+
+	var errs []error
+	described := make(map[string]bool)
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			if method == "parameters" {
+				continue
+			}
+			described[strings.ToUpper(method)+" "+path] = true
+		}
+	}
+	for _, route := range routes {
+		// Группы с middleware регистрируют служебные маршруты для ответа 404.
+		if route.Method == echo.RouteNotFound {
+			continue
+		}
+		key := route.Method + " " + routeParamRegexp.ReplaceAllString(route.Path, "{$1}")
+		if !described[key] {
+			errs = append(errs, fmt.Errorf("route %s is not described in openapi spec", key))
+		}
+		delete(described, key)
+	}
+	for key := range described {
+		errs = append(errs, fmt.Errorf("openapi operation %s has no route", key))
+	}
+
+	for _, match := range schemaRefRegexp.FindAllStringSubmatch(string(data), -1) {
+		if _, ok := openAPISchemas[match[1]]; !ok {
+			errs = append(errs, fmt.Errorf("openapi schema %s is not defined", match[1]))
+		}
+	}
+
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+
+	return errors.Join(errs...)
+}
+
+// buildOpenAPISpec дополняет встроенную спецификацию схемами DTO.
+func buildOpenAPISpec() ([]byte, error) {
+	data, err := openAPIFiles.ReadFile("openapi/openapi.json")
+	if err != nil {
+		return nil, fmt.Errorf("error reading openapi spec: %w", err)
+	}
+
+	var document map[string]interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("error parsing openapi spec: %w", err)
+	}
+	components, _ := document["components"].(map[string]interface{})
+	if components == nil {
+		components = make(map[string]interface{})
+		document["components"] = components
+	}
+	components["schemas"] = api.Schemas(openAPISchemas)
+
+	return json.Marshal(document)
+}
+
+func (s *EchoHTTPServer) handleOpenAPI(ctx echo.Context) error {
+	return ctx.JSONBlob(http.StatusOK, s.openAPISpec)
+}
+
+func (s *EchoHTTPServer) handleDocs(ctx echo.Context) error {
+	page, err := openAPIFiles.ReadFile("openapi/index.html")
+	if err != nil {
+		return err
+	}
+
+	return ctx.HTMLBlob(http.StatusOK, page)
+}
+
+func (s *EchoHTTPServer) handleDocsAsset(ctx echo.Context) error {
+	file := ctx.Param("file")
+	contentType, ok := docsAssetTypes[file]
+	if !ok {
+		return apperrors.RouteNotFound
+	}
+
+	data, err := openAPIFiles.ReadFile(docsAssetsDir + file)
+	if err != nil {
+		return err
+	}
+
+	return ctx.Blob(http.StatusOK, contentType, data)
+}
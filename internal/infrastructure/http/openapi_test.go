@@ -0,0 +1,90 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"rest_clickhouse/pkg/logger/zerolog"
+	"strings"
+	"testing"
+)
+
+func newTestServer(t *testing.T) *EchoHTTPServer {
+	t.Helper()
+
+	log, err := zerolog.NewZeroLog(io.Discard, zerolog.Config{})
+	if err != nil {
+		t.Fatalf("error creating logger: %v", err)
+	}
+	server, err := NewEchoHTTPServer("0", MiddlewareConfig{}, nil, nil, nil, nil, nil, nil, nil, nil, nil, log)
+	if err != nil {
+		t.Fatalf("error creating server: %v", err)
+	}
+	server.echo.HTTPErrorHandler = errorHandler(log)
+
+	return server
+}
+
+func TestOpenAPISpecMatchesRoutes(t *testing.T) {
+	server := newTestServer(t)
+
+	if err := checkOpenAPISpec(server.echo.Routes()); err != nil {
+		t.Fatalf("openapi spec does not match routes:\n%v", err)
+	}
+}
+
+func TestOpenAPISpecHasSchemas(t *testing.T) {
+	spec, err := buildOpenAPISpec()
+	if err != nil {
+		t.Fatalf("error building openapi spec: %v", err)
+	}
+
+	var document struct {
+		Components struct {
+			Schemas map[string]json.RawMessage `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(spec, &document); err != nil {
+		t.Fatalf("error parsing openapi spec: %v", err)
+	}
+	for name := range openAPISchemas {
+		if _, ok := document.Components.Schemas[name]; !ok {
+			t.Errorf("schema %s is missing in components", name)
+		}
+	}
+}
+
+func TestDocsServeEmbeddedAssets(t *testing.T) {
+	server := newTestServer(t)
+
+	tests := []struct {
+		path        string
+		status      int
+		contentType string
+	}{
+		{path: docsPath, status: http.StatusOK, contentType: "text/html"},
+		{path: "/docs/swagger-ui.css", status: http.StatusOK, contentType: "text/css"},
+		{path: "/docs/swagger-ui-bundle.js", status: http.StatusOK, contentType: "text/javascript"},
+		{path: "/docs/index.html", status: http.StatusNotFound},
+	}
+	for _, test := range tests {
+		rec := httptest.NewRecorder()
+		server.echo.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.path, nil))
+
+		if rec.Code != test.status {
+			t.Errorf("GET %s: status %d, want %d", test.path, rec.Code, test.status)
+			continue
+		}
+		if test.contentType != "" && !strings.HasPrefix(rec.Header().Get("Content-Type"), test.contentType) {
+			t.Errorf("GET %s: content type %q, want %s", test.path, rec.Header().Get("Content-Type"), test.contentType)
+		}
+	}
+
+	// Страница не должна ссылаться на внешние ресурсы.
+	rec := httptest.NewRecorder()
+	server.echo.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, docsPath, nil))
+	if body := rec.Body.String(); strings.Contains(body, "https://") {
+		t.Errorf("docs page references external resources:\n%s", body)
+	}
+}
@@ -0,0 +1,120 @@
+package http
+
+import (
+	"math"
+	"rest_clickhouse/internal/apperrors"
+	"rest_clickhouse/pkg/auth"
+	"rest_clickhouse/pkg/logger"
+	"rest_clickhouse/pkg/metrics"
+	"rest_clickhouse/pkg/ratelimit"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	rateLimitScopeClient  = "client"
+	rateLimitScopeProject = "project"
+)
+
+// rateLimitMiddleware ограничивает запросы вызывающего и проекта корзинами токенов.
+// Вызывающий - субъект JWT (user:<sub>) или API ключ (apikey:<id>), без аутентификации - IP адрес (ip:<адрес>).
+// Лимит проекта расходуют только вызывающие с ролью в нем, чтобы чужие запросы, которые получат 403,
+// не исчерпывали его. Каждый ответ содержит заголовки RateLimit-* по самому исчерпанному лимиту,
+// превышение - 429 с Retry-After. Ошибка лимитера не блокирует запрос.
+func rateLimitMiddleware(limiter ratelimit.Limiter, settings *ratelimit.Settings, log logger.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			policy := settings.Load()
+			if path := ctx.Path(); !policy.Enabled || path == livenessPath || path == readinessPath {
+				return next(ctx)
+			}
+
+			req := ctx.Request()
+			route := req.Method + " " + ctx.Path()
+
+			actor, authenticated := auth.ActorFromContext(req.Context())
+			client := rateLimitClient(ctx, actor)
+
+			clientKey, clientLimit := "client:"+client, policy.Client
+			if limit, ok := policy.Routes[route]; ok {
+				clientKey, clientLimit = "route:"+route+":"+client, limit
+			}
+			if limit, ok := policy.Overrides[client]; ok {
+				clientLimit = limit
+			}
+
+			result, err := limiter.Allow(req.Context(), clientKey, clientLimit)
+			if err != nil {
+				log.WithContext(req.Context()).ErrorF("rate limiter error: %v", err)
+				return next(ctx)
+			}
+			if !result.Allowed {
+				return tooManyRequests(ctx, route, rateLimitScopeClient, result)
+			}
+
+			if projectId, ok := logger.ProjectIdFromContext(req.Context()); ok && (!authenticated || hasProjectRole(actor, projectId)) {
+				project := "project:" + strconv.Itoa(projectId)
+				projectLimit := policy.Project
+				if limit, ok := policy.Overrides[project]; ok {
+					projectLimit = limit
+				}
+
+				projectResult, err := limiter.Allow(req.Context(), project, projectLimit)
+				if err != nil {
+					log.WithContext(req.Context()).ErrorF("rate limiter error: %v", err)
+					return next(ctx)
+				}
+				if !projectResult.Allowed {
+					return tooManyRequests(ctx, route, rateLimitScopeProject, projectResult)
+				}
+				if projectResult.Remaining < result.Remaining {
+					result = projectResult
+				}
+			}
+
+			setRateLimitHeaders(ctx, result)
+
+			return next(ctx)
+		}
+	}
+}
+
+// rateLimitClient возвращает ключ вызывающего. Префиксы разделяют пространства ключей,
+// чтобы субъект JWT не совпал с ключом API ключа, IP адреса или проекта.
+func rateLimitClient(ctx echo.Context, actor *auth.Actor) string {
+	if actor == nil {
+		return "ip:" + ctx.RealIP()
+	}
+	if actor.Method == auth.MethodApiKey {
+		return actor.Id
+	}
+
+	return "user:" + actor.Id
+}
+
+func hasProjectRole(actor *auth.Actor, projectId int) bool {
+	_, ok := actor.Role(projectId)
+	return ok
+}
+
+func tooManyRequests(ctx echo.Context, route, scope string, result ratelimit.Result) error {
+	metrics.HTTPRateLimitedTotal.WithLabelValues(route, scope).Inc()
+
+	setRateLimitHeaders(ctx, result)
+	ctx.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(ceilSeconds(result.RetryAfter)))
+
+	return apperrors.TooManyRequests.WithDetails(scope)
+}
+
+func setRateLimitHeaders(ctx echo.Context, result ratelimit.Result) {
+	header := ctx.Response().Header()
+	header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+	header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
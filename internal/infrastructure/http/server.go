@@ -2,51 +2,186 @@ package http
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"rest_clickhouse/pkg/logger"
 
 	"github.com/labstack/echo/v4"
 )
 
+const tracingServiceName = "rest_clickhouse"
+
 type HTTPServer interface {
-	Start()
+	Start() error
 	Stop(ctx context.Context)
 }
 
 type EchoHTTPServer struct {
-	echo         *echo.Echo
-	serverPort   string
-	goodsService GoodsService
-	logger       logger.Logger
+	echo              *echo.Echo
+	serverPort        string
+	middlewareConfig  MiddlewareConfig
+	goodsService      GoodsService
+	categoriesService CategoriesService
+	attributesService AttributesService
+	streamService     GoodsStreamService
+	webhooksService   WebhooksService
+	apiKeysService    ApiKeysService
+	auditService      AuditService
+	graphQLService    GraphQLService
+	healthService     HealthService
+	openAPISpec       []byte
+	logger            logger.Logger
 }
 
 func NewEchoHTTPServer(
 	ServerPort string,
+	middlewareConfig MiddlewareConfig,
 	goodsService GoodsService,
+	categoriesService CategoriesService,
+	attributesService AttributesService,
+	streamService GoodsStreamService,
+	webhooksService WebhooksService,
+	apiKeysService ApiKeysService,
+	auditService AuditService,
+	graphQLService GraphQLService,
+	healthService HealthService,
 	logger logger.Logger,
-) *EchoHTTPServer {
+) (*EchoHTTPServer, error) {
 	server := &EchoHTTPServer{
-		echo:         echo.New(),
-		goodsService: goodsService,
-		serverPort:   ServerPort,
-		logger:       logger,
+		echo:              echo.New(),
+		goodsService:      goodsService,
+		categoriesService: categoriesService,
+		attributesService: attributesService,
+		streamService:     streamService,
+		webhooksService:   webhooksService,
+		apiKeysService:    apiKeysService,
+		auditService:      auditService,
+		graphQLService:    graphQLService,
+		healthService:     healthService,
+		serverPort:        ServerPort,
+		middlewareConfig:  middlewareConfig,
+		logger:            logger,
+	}
+
+	server.registerRoutes()
+
+	// Соответствие спецификации маршрутам проверяет openapi_test.go, здесь расхождение только логируется.
+	if err := checkOpenAPISpec(server.echo.Routes()); err != nil {
+		logger.WarnF("openapi spec does not match routes: %v", err)
+	}
+	spec, err := buildOpenAPISpec()
+	if err != nil {
+		return nil, fmt.Errorf("error building openapi spec: %w", err)
 	}
+	server.openAPISpec = spec
 
-	return server
+	return server, nil
 }
 
-func (s *EchoHTTPServer) Start() {
-	s.echo.POST("/goods/create/:projectId", s.handleCreateGood)
-	s.echo.GET("/goods/list/:limit/:offset", s.handleGetGoods)
-	s.echo.DELETE("/good/remove/:id/:projectId", s.handleRemoveGood)
-	s.echo.PATCH("/good/update/:id/:projectId", s.handleUpdateGood)
+// Start занимает порт сразу, чтобы ошибка прослушивания остановила запуск приложения, и обслуживает запросы в фоне.
+func (s *EchoHTTPServer) Start() error {
+	s.echo.HTTPErrorHandler = errorHandler(s.logger)
+	s.useMiddlewares()
 
-	func() {
-		port := fmt.Sprintf(":%v", s.serverPort)
-		if err := s.echo.Start(port); err != nil {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%v", s.serverPort))
+	if err != nil {
+		return fmt.Errorf("http listen error: %w", err)
+	}
+	s.echo.Listener = listener
+
+	go func() {
+		if err := s.echo.Start(""); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			s.logger.Error("Echo error:", err)
 		}
 	}()
+
+	return nil
+}
+
+func (s *EchoHTTPServer) registerRoutes() {
+	s.echo.GET(livenessPath, s.handleLiveness)
+	s.echo.GET(readinessPath, s.handleReadiness)
+
+	v2 := s.echo.Group(apiV2Prefix, contentNegotiationMiddleware)
+	project := v2.Group(v2ProjectPrefix)
+
+	project.GET("/goods", s.handleGetGoods)
+	project.POST("/goods", s.handleCreateGood)
+	project.GET("/goods/search", s.handleSearchGoods)
+	project.GET("/goods/stream", s.handleStreamGoods)
+	project.GET("/goods/:id", s.handleGetGood)
+	project.PUT("/goods/:id", s.handleReplaceGood)
+	project.PATCH("/goods/:id", s.handleUpdateGood)
+	project.DELETE("/goods/:id", s.handleRemoveGood)
+	project.PUT("/goods/:id/tags", s.handleSetGoodTags)
+	project.PUT("/goods/:id/category", s.handleSetGoodCategory)
+	project.GET("/tags", s.handleGetTags)
+
+	project.GET("/categories", s.handleGetCategories)
+	project.POST("/categories", s.handleCreateCategory)
+	project.DELETE("/categories/:id", s.handleRemoveCategory)
+
+	project.GET("/attributes", s.handleGetAttributes)
+	project.POST("/attributes", s.handleCreateAttribute)
+	project.DELETE("/attributes/:name", s.handleRemoveAttribute)
+
+	project.GET("/webhooks", s.handleGetWebhooks)
+	project.POST("/webhooks", s.handleCreateWebhook)
+	project.DELETE("/webhooks/:id", s.handleRemoveWebhook)
+	project.GET("/webhooks/:id/deliveries", s.handleGetWebhookDeliveries)
+	project.POST("/webhook-deliveries/:deliveryId/redeliver", s.handleRedeliverWebhook)
+
+	project.GET("/api-keys", s.handleGetApiKeys)
+	project.POST("/api-keys", s.handleCreateApiKey)
+	project.DELETE("/api-keys/:id", s.handleRevokeApiKey)
+	project.POST("/api-keys/:id/rotate", s.handleRotateApiKey)
+
+	v2.GET("/audit", s.handleGetAudit)
+
+	// Маршруты v1 остаются для существующих клиентов и ссылаются на замену в v2.
+	goods := apiV2Prefix + v2ProjectPrefix + "/goods"
+	s.echo.POST("/goods/create/:projectId", s.handleCreateGood, deprecatedRoute(goods))
+	s.echo.GET("/goods/list/:limit/:offset", s.handleGetGoods, deprecatedRoute(goods))
+	s.echo.GET("/goods/search", s.handleSearchGoods, deprecatedRoute(goods+"/search"))
+	s.echo.GET("/goods/stream", s.handleStreamGoods, deprecatedRoute(goods+"/stream"))
+	s.echo.DELETE("/good/remove/:id/:projectId", s.handleRemoveGood, deprecatedRoute(goods+"/:id"))
+	s.echo.PATCH("/good/update/:id/:projectId", s.handleUpdateGood, deprecatedRoute(goods+"/:id"))
+	s.echo.PUT("/good/tags/:id/:projectId", s.handleSetGoodTags, deprecatedRoute(goods+"/:id/tags"))
+	s.echo.PATCH("/good/category/:id/:projectId", s.handleSetGoodCategory, deprecatedRoute(goods+"/:id/category"))
+	s.echo.GET("/tags/list/:projectId", s.handleGetTags, deprecatedRoute(apiV2Prefix+v2ProjectPrefix+"/tags"))
+
+	categories := apiV2Prefix + v2ProjectPrefix + "/categories"
+	s.echo.POST("/categories/create/:projectId", s.handleCreateCategory, deprecatedRoute(categories))
+	s.echo.GET("/categories/list/:projectId", s.handleGetCategories, deprecatedRoute(categories))
+	s.echo.DELETE("/category/remove/:id/:projectId", s.handleRemoveCategory, deprecatedRoute(categories+"/:id"))
+
+	attributes := apiV2Prefix + v2ProjectPrefix + "/attributes"
+	s.echo.POST("/attributes/create/:projectId", s.handleCreateAttribute, deprecatedRoute(attributes))
+	s.echo.GET("/attributes/list/:projectId", s.handleGetAttributes, deprecatedRoute(attributes))
+	s.echo.DELETE("/attribute/remove/:name/:projectId", s.handleRemoveAttribute, deprecatedRoute(attributes+"/:name"))
+
+	webhooks := apiV2Prefix + v2ProjectPrefix + "/webhooks"
+	s.echo.POST("/webhooks/create/:projectId", s.handleCreateWebhook, deprecatedRoute(webhooks))
+	s.echo.GET("/webhooks/list/:projectId", s.handleGetWebhooks, deprecatedRoute(webhooks))
+	s.echo.DELETE("/webhook/remove/:id/:projectId", s.handleRemoveWebhook, deprecatedRoute(webhooks+"/:id"))
+	s.echo.GET("/webhook/deliveries/:id/:projectId", s.handleGetWebhookDeliveries, deprecatedRoute(webhooks+"/:id/deliveries"))
+	s.echo.POST("/webhook/redeliver/:deliveryId/:projectId", s.handleRedeliverWebhook, deprecatedRoute(apiV2Prefix+v2ProjectPrefix+"/webhook-deliveries/:deliveryId/redeliver"))
+
+	apiKeys := apiV2Prefix + v2ProjectPrefix + "/api-keys"
+	s.echo.POST("/apikeys/create/:projectId", s.handleCreateApiKey, deprecatedRoute(apiKeys))
+	s.echo.GET("/apikeys/list/:projectId", s.handleGetApiKeys, deprecatedRoute(apiKeys))
+	s.echo.DELETE("/apikey/revoke/:id/:projectId", s.handleRevokeApiKey, deprecatedRoute(apiKeys+"/:id"))
+	s.echo.POST("/apikey/rotate/:id/:projectId", s.handleRotateApiKey, deprecatedRoute(apiKeys+"/:id/rotate"))
+
+	s.echo.GET("/audit", s.handleGetAudit, deprecatedRoute(apiV2Prefix+"/audit"))
+
+	s.echo.POST(graphQLPath, s.handleGraphQL)
+
+	s.echo.GET(openAPIPath, s.handleOpenAPI)
+	s.echo.GET(docsPath, s.handleDocs)
+	s.echo.GET(docsAssetsPath, s.handleDocsAsset)
 }
 
 func (s *EchoHTTPServer) Stop(ctx context.Context) {
@@ -64,6 +199,10 @@ func (s *EchoHTTPServer) handleGetGoods(ctx echo.Context) error {
 	return s.goodsService.HandleGetGood(ctx)
 }
 
+func (s *EchoHTTPServer) handleGetGood(ctx echo.Context) error {
+	return s.goodsService.HandleGetGoodById(ctx)
+}
+
 func (s *EchoHTTPServer) handleRemoveGood(ctx echo.Context) error {
 	return s.goodsService.HandleRemoveGood(ctx)
 }
@@ -71,3 +210,103 @@ func (s *EchoHTTPServer) handleRemoveGood(ctx echo.Context) error {
 func (s *EchoHTTPServer) handleUpdateGood(ctx echo.Context) error {
 	return s.goodsService.HandleUpdateGoods(ctx)
 }
+
+func (s *EchoHTTPServer) handleReplaceGood(ctx echo.Context) error {
+	return s.goodsService.HandleReplaceGood(ctx)
+}
+
+func (s *EchoHTTPServer) handleSearchGoods(ctx echo.Context) error {
+	return s.goodsService.HandleSearchGoods(ctx)
+}
+
+func (s *EchoHTTPServer) handleStreamGoods(ctx echo.Context) error {
+	return s.streamService.HandleStreamGoods(ctx)
+}
+
+func (s *EchoHTTPServer) handleSetGoodTags(ctx echo.Context) error {
+	return s.goodsService.HandleSetGoodTags(ctx)
+}
+
+func (s *EchoHTTPServer) handleSetGoodCategory(ctx echo.Context) error {
+	return s.goodsService.HandleSetGoodCategory(ctx)
+}
+
+func (s *EchoHTTPServer) handleGetTags(ctx echo.Context) error {
+	return s.goodsService.HandleGetTags(ctx)
+}
+
+func (s *EchoHTTPServer) handleCreateCategory(ctx echo.Context) error {
+	return s.categoriesService.HandleCreateCategory(ctx)
+}
+
+func (s *EchoHTTPServer) handleGetCategories(ctx echo.Context) error {
+	return s.categoriesService.HandleGetCategories(ctx)
+}
+
+func (s *EchoHTTPServer) handleRemoveCategory(ctx echo.Context) error {
+	return s.categoriesService.HandleRemoveCategory(ctx)
+}
+
+func (s *EchoHTTPServer) handleCreateAttribute(ctx echo.Context) error {
+	return s.attributesService.HandleCreateAttribute(ctx)
+}
+
+func (s *EchoHTTPServer) handleGetAttributes(ctx echo.Context) error {
+	return s.attributesService.HandleGetAttributes(ctx)
+}
+
+func (s *EchoHTTPServer) handleRemoveAttribute(ctx echo.Context) error {
+	return s.attributesService.HandleRemoveAttribute(ctx)
+}
+
+func (s *EchoHTTPServer) handleCreateWebhook(ctx echo.Context) error {
+	return s.webhooksService.HandleCreateWebhook(ctx)
+}
+
+func (s *EchoHTTPServer) handleGetWebhooks(ctx echo.Context) error {
+	return s.webhooksService.HandleGetWebhooks(ctx)
+}
+
+func (s *EchoHTTPServer) handleRemoveWebhook(ctx echo.Context) error {
+	return s.webhooksService.HandleRemoveWebhook(ctx)
+}
+
+func (s *EchoHTTPServer) handleGetWebhookDeliveries(ctx echo.Context) error {
+	return s.webhooksService.HandleGetDeliveries(ctx)
+}
+
+func (s *EchoHTTPServer) handleRedeliverWebhook(ctx echo.Context) error {
+	return s.webhooksService.HandleRedeliver(ctx)
+}
+
+func (s *EchoHTTPServer) handleCreateApiKey(ctx echo.Context) error {
+	return s.apiKeysService.HandleCreateApiKey(ctx)
+}
+
+func (s *EchoHTTPServer) handleGetApiKeys(ctx echo.Context) error {
+	return s.apiKeysService.HandleGetApiKeys(ctx)
+}
+
+func (s *EchoHTTPServer) handleRevokeApiKey(ctx echo.Context) error {
+	return s.apiKeysService.HandleRevokeApiKey(ctx)
+}
+
+func (s *EchoHTTPServer) handleRotateApiKey(ctx echo.Context) error {
+	return s.apiKeysService.HandleRotateApiKey(ctx)
+}
+
+func (s *EchoHTTPServer) handleGetAudit(ctx echo.Context) error {
+	return s.auditService.HandleGetAudit(ctx)
+}
+
+func (s *EchoHTTPServer) handleGraphQL(ctx echo.Context) error {
+	return s.graphQLService.HandleGraphQL(ctx)
+}
+
+func (s *EchoHTTPServer) handleLiveness(ctx echo.Context) error {
+	return s.healthService.HandleLiveness(ctx)
+}
+
+func (s *EchoHTTPServer) handleReadiness(ctx echo.Context) error {
+	return s.healthService.HandleReadiness(ctx)
+}
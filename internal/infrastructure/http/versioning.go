@@ -0,0 +1,116 @@
+package http
+
+import (
+	"mime"
+	"net/http"
+	"rest_clickhouse/internal/apperrors"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	apiV2Prefix       = "/api/v2"
+	v2ProjectPrefix   = "/projects/:projectId"
+	eventStreamMIME   = "text/event-stream"
+	mergePatchMIME    = "application/merge-patch+json"
+	deprecationHeader = "Deprecation"
+)
+
+// contentNegotiationMiddleware проверяет для маршрутов v2, что клиент принимает тип ответа маршрута (иначе 406)
+// и передает тело запроса в JSON (иначе 415), PATCH принимает и application/merge-patch+json.
+// Поток товаров отвечает text/event-stream, остальные маршруты - JSON.
+func contentNegotiationMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		req := ctx.Request()
+
+		produces := echo.MIMEApplicationJSON
+		if ctx.Path() == apiV2Prefix+v2ProjectPrefix+"/goods/stream" {
+			produces = eventStreamMIME
+		}
+		if !acceptsMediaType(req.Header.Get(echo.HeaderAccept), produces) {
+			return apperrors.NotAcceptable.WithDetails(produces)
+		}
+
+		if hasBody(req) {
+			contentType := req.Header.Get(echo.HeaderContentType)
+			mediaType, _, err := mime.ParseMediaType(contentType)
+			mergePatch := req.Method == http.MethodPatch && mediaType == mergePatchMIME
+			if err != nil || mediaType != echo.MIMEApplicationJSON && !mergePatch {
+				return apperrors.UnsupportedMediaType.WithDetails(contentType)
+			}
+		}
+
+		return next(ctx)
+	}
+}
+
+// acceptsMediaType разбирает заголовок Accept, отсутствующий заголовок принимает любой тип.
+func acceptsMediaType(accept, mediaType string) bool {
+	if strings.TrimSpace(accept) == "" {
+		return true
+	}
+
+	mainType, _, _ := strings.Cut(mediaType, "/")
+	for _, item := range strings.Split(accept, ",") {
+		accepted, params, err := mime.ParseMediaType(strings.TrimSpace(item))
+		if err != nil {
+			continue
+		}
+		if weight, err := strconv.ParseFloat(params["q"], 64); err == nil && weight == 0 {
+			continue
+		}
+		if accepted == "*/*" || accepted == mainType+"/*" || accepted == mediaType {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasBody(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch:
+		return req.ContentLength > 0 || len(req.TransferEncoding) > 0
+	default:
+		return false
+	}
+}
+
+// deprecatedRoute помечает устаревший маршрут заголовком Deprecation и ссылкой на маршрут v2.
+// Параметры в successor подставляются из пути или query запроса, без них ссылка не отдается.
+func deprecatedRoute(successor string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			header := ctx.Response().Header()
+			header.Set(deprecationHeader, "true")
+			if link, ok := successorLink(ctx, successor); ok {
+				header.Add("Link", "<"+link+`>; rel="successor-version"`)
+			}
+
+			return next(ctx)
+		}
+	}
+}
+
+func successorLink(ctx echo.Context, successor string) (string, bool) {
+	segments := strings.Split(successor, "/")
+	for i, segment := range segments {
+		name, ok := strings.CutPrefix(segment, ":")
+		if !ok {
+			continue
+		}
+
+		value := ctx.Param(name)
+		if value == "" {
+			value = ctx.QueryParam(name)
+		}
+		if value == "" {
+			return "", false
+		}
+		segments[i] = value
+	}
+
+	return strings.Join(segments, "/"), true
+}
@@ -0,0 +1,155 @@
+package http
+
+import (
+	"net/http"
+	"rest_clickhouse/internal/api"
+	"rest_clickhouse/internal/apperrors"
+	"rest_clickhouse/internal/infrastructure/usecase/interactors"
+	"rest_clickhouse/pkg/auth"
+	"rest_clickhouse/pkg/logger"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+type WebhooksService interface {
+	HandleCreateWebhook(ctx echo.Context) error
+	HandleGetWebhooks(ctx echo.Context) error
+	HandleRemoveWebhook(ctx echo.Context) error
+	HandleGetDeliveries(ctx echo.Context) error
+	HandleRedeliver(ctx echo.Context) error
+}
+
+type webhooksService struct {
+	webhooksInteractor interactors.WebhooksInteractor
+	logger             logger.Logger
+}
+
+func NewWebhooksService(webhooksInteractor interactors.WebhooksInteractor, logger logger.Logger) WebhooksService {
+	return &webhooksService{
+		webhooksInteractor: webhooksInteractor,
+		logger:             logger,
+	}
+}
+
+func (c *webhooksService) HandleCreateWebhook(ctx echo.Context) error {
+	webhook := new(api.Webhook)
+	projectId, err := pathParamInt(ctx, "projectId")
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeProject(ctx, projectId, auth.RoleAdmin); err != nil {
+		return err
+	}
+
+	if err := bindBody(ctx, webhook); err != nil {
+		return err
+	}
+
+	webhook.ProjectId = projectId
+
+	webhookDTO, err := c.webhooksInteractor.CreateWebhook(ctx.Request().Context(), webhook)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusCreated, api.GetCreatedWebhook(webhookDTO))
+}
+
+func (c *webhooksService) HandleGetWebhooks(ctx echo.Context) error {
+	projectId, err := pathParamInt(ctx, "projectId")
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeProject(ctx, projectId, auth.RoleAdmin); err != nil {
+		return err
+	}
+
+	webhookModels, err := c.webhooksInteractor.GetList(ctx.Request().Context(), projectId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, api.GetWebhookList(webhookModels))
+}
+
+func (c *webhooksService) HandleRemoveWebhook(ctx echo.Context) error {
+	id, err := pathParamInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	projectId, err := pathParamInt(ctx, "projectId")
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeProject(ctx, projectId, auth.RoleAdmin); err != nil {
+		return err
+	}
+
+	err = c.webhooksInteractor.RemoveWebhook(ctx.Request().Context(), id, projectId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+func (c *webhooksService) HandleGetDeliveries(ctx echo.Context) error {
+	id, err := pathParamInt(ctx, "id")
+	if err != nil {
+		return err
+	}
+
+	projectId, err := pathParamInt(ctx, "projectId")
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeProject(ctx, projectId, auth.RoleAdmin); err != nil {
+		return err
+	}
+
+	limit, err := queryParamInt(ctx, "limit")
+	if err != nil {
+		return err
+	}
+
+	offset, err := queryParamInt(ctx, "offset")
+	if err != nil {
+		return err
+	}
+
+	deliveryModels, err := c.webhooksInteractor.GetDeliveries(ctx.Request().Context(), id, projectId, limit, offset)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusOK, api.GetWebhookDeliveryList(deliveryModels))
+}
+
+func (c *webhooksService) HandleRedeliver(ctx echo.Context) error {
+	deliveryId, err := strconv.ParseInt(ctx.Param("deliveryId"), 10, 64)
+	if err != nil {
+		return apperrors.InvalidRequest.WithField("deliveryId", "must be an integer")
+	}
+
+	projectId, err := pathParamInt(ctx, "projectId")
+	if err != nil {
+		return err
+	}
+
+	if err := authorizeProject(ctx, projectId, auth.RoleAdmin); err != nil {
+		return err
+	}
+
+	deliveryDTO, err := c.webhooksInteractor.Redeliver(ctx.Request().Context(), deliveryId, projectId)
+	if err != nil {
+		return err
+	}
+
+	return ctx.JSON(http.StatusAccepted, api.GetWebhookDelivery(deliveryDTO))
+}
@@ -0,0 +1,175 @@
+package nats_client
+
+import (
+	"context"
+	"encoding/json"
+	"rest_clickhouse/internal/infrastructure/queue"
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	"rest_clickhouse/pkg/logger"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	subscriberBufferSize = 64
+	// ResetEvent сообщает подписчику, что часть событий после его номера вытеснена из буфера
+	// и состояние нужно перечитать. У события нет товара, его номер - последний выданный.
+	ResetEvent = "reset"
+)
+
+// BroadcastEvent содержит изменение товара с порядковым номером для возобновления потока.
+type BroadcastEvent struct {
+	Id    uint64
+	Good  *repository.GoodModel
+	Type  string
+	Audit repository.AuditModel
+}
+
+// EventBroadcaster держит одну подписку на топик событий, нумерует события,
+// хранит последние из них в кольцевом буфере и раздает их подписчикам.
+type EventBroadcaster struct {
+	sub    queue.Subscriber
+	logger logger.Logger
+	unsub  func(ctx context.Context) error
+
+	mu          sync.Mutex
+	stopped     bool
+	lastId      uint64
+	buffer      []BroadcastEvent
+	next        int
+	full        bool
+	subscribers map[chan BroadcastEvent]struct{}
+}
+
+func NewEventBroadcaster(sub queue.Subscriber, bufferSize int, logger logger.Logger) *EventBroadcaster {
+	return &EventBroadcaster{
+		sub:    sub,
+		logger: logger,
+		// Нумерация начинается с текущего времени, чтобы после перезапуска номера продолжали расти.
+		lastId:      uint64(time.Now().UnixMicro()),
+		buffer:      make([]BroadcastEvent, bufferSize),
+		subscribers: make(map[chan BroadcastEvent]struct{}),
+	}
+}
+
+func (b *EventBroadcaster) Start() error {
+	unsub, err := b.sub.Sub(EventTopicName, b.handle)
+	if err != nil {
+		return err
+	}
+	b.unsub = unsub
+
+	return nil
+}
+
+// Stop отписывается от топика и закрывает каналы подписчиков, чтобы открытые потоки завершились.
+// Подписки после остановки сразу получают закрытый канал.
+func (b *EventBroadcaster) Stop(ctx context.Context) error {
+	var err error
+	if b.unsub != nil {
+		err = b.unsub(ctx)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stopped = true
+	for ch := range b.subscribers {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+
+	return err
+}
+
+// Subscribe возвращает события из буфера после lastEventId и канал новых событий.
+// Если события после lastEventId уже вытеснены из буфера, вместо них возвращается одно событие ResetEvent.
+// Канал закрывается, если подписчик не успевает читать события; клиент может
+// переподключиться с последним полученным номером.
+func (b *EventBroadcaster) Subscribe(lastEventId uint64) ([]BroadcastEvent, <-chan BroadcastEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var backlog []BroadcastEvent
+	if lastEventId != 0 {
+		backlog = b.since(lastEventId)
+	}
+
+	ch := make(chan BroadcastEvent, subscriberBufferSize)
+	if b.stopped {
+		close(ch)
+		return backlog, ch, func() {}
+	}
+	b.subscribers[ch] = struct{}{}
+
+	cancel := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[ch]; ok {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+
+	return backlog, ch, cancel
+}
+
+func (b *EventBroadcaster) handle(m *nats.Msg) {
+	var goodEvent repository.GoodEvent
+	if err := json.Unmarshal(m.Data, &goodEvent); err != nil {
+		b.logger.ErrorF("broadcaster unmarshal error: %v", err)
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastId++
+	event := BroadcastEvent{Id: b.lastId, Good: &goodEvent.GoodModel, Type: goodEvent.Type, Audit: goodEvent.AuditModel}
+
+	if len(b.buffer) > 0 {
+		b.buffer[b.next] = event
+		b.next = (b.next + 1) % len(b.buffer)
+		if b.next == 0 {
+			b.full = true
+		}
+	}
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			b.logger.WarnF("broadcaster: subscriber is too slow, dropping it")
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// since возвращает события буфера с номером больше lastEventId в порядке поступления
+// или ResetEvent, если следующее за lastEventId событие в буфере уже не хранится.
+// Номера событий идут подряд, а после перезапуска начинаются заново с большего значения.
+func (b *EventBroadcaster) since(lastEventId uint64) []BroadcastEvent {
+	ordered := b.buffer[:b.next]
+	if b.full {
+		ordered = append(append([]BroadcastEvent{}, b.buffer[b.next:]...), b.buffer[:b.next]...)
+	}
+
+	oldestId := b.lastId + 1
+	if len(ordered) > 0 {
+		oldestId = ordered[0].Id
+	}
+	if lastEventId+1 < oldestId {
+		return []BroadcastEvent{{Id: b.lastId, Type: ResetEvent}}
+	}
+
+	events := make([]BroadcastEvent, 0)
+	for _, event := range ordered {
+		if event.Id > lastEventId {
+			events = append(events, event)
+		}
+	}
+
+	return events
+}
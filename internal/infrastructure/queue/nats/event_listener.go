@@ -3,58 +3,134 @@ package nats_client
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"rest_clickhouse/internal/infrastructure/queue"
 	"rest_clickhouse/internal/infrastructure/usecase/repository"
 	"rest_clickhouse/pkg/logger"
+	"rest_clickhouse/pkg/metrics"
+	"rest_clickhouse/pkg/tracing"
+	"time"
 
 	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel/trace"
 )
 
-const EventTopicName = "events"
+const (
+	EventTopicName = "events"
+	// stopFlushTimeout - время на запись последней пачки, если ctx остановки истек во время отписки.
+	stopFlushTimeout = 10 * time.Second
+)
 
 type EventListener struct {
 	sub              queue.Subscriber
 	eventsRepository repository.EventsRepository
 	logger           logger.Logger
 	ctx              context.Context
+	unsub            func(ctx context.Context) error
+	batch            *repository.EventsBatchSettings
+	stopFlush        chan struct{}
+	flushDone        chan struct{}
 }
 
-func NewEventListener(ctx context.Context, sub queue.Subscriber, logRepository repository.EventsRepository, logger logger.Logger) *EventListener {
+func NewEventListener(ctx context.Context, sub queue.Subscriber, logRepository repository.EventsRepository, batch *repository.EventsBatchSettings, logger logger.Logger) *EventListener {
 	return &EventListener{
 		sub:              sub,
 		eventsRepository: logRepository,
 		logger:           logger,
 		ctx:              ctx,
+		batch:            batch,
+		stopFlush:        make(chan struct{}),
+		flushDone:        make(chan struct{}),
 	}
 }
 
-func (listen *EventListener) ListenTopic() {
-	listen.logger.Info("Event Listener started!")
+func (listen *EventListener) ListenTopic() error {
 	unsub, err := listen.sub.Sub(EventTopicName, func(m *nats.Msg) {
-		listen.logger.Info("Received a message: %s\n", string(m.Data))
+		ctx, span := tracing.StartSpan(
+			tracing.ExtractNatsHeader(listen.ctx, m.Header),
+			"nats.consume "+EventTopicName,
+			trace.WithSpanKind(trace.SpanKindConsumer),
+		)
+		defer span.End()
 
-		var goodModel repository.GoodModel
-		err := json.Unmarshal(m.Data, &goodModel)
+		log := listen.logger.WithContext(ctx)
+		log.Sampled().InfoF("Received a message: %s", m.Data)
 
-		if err != nil {
-			listen.logger.Error(err)
+		// Подписка core NATS не требует подтверждения: некорректное сообщение только учитывается
+		// в метрике и отбрасывается, чтобы в Clickhouse не попало пустое событие.
+		var event repository.GoodEvent
+		if err := json.Unmarshal(m.Data, &event); err != nil {
+			metrics.NatsConsumeErrorsTotal.WithLabelValues(EventTopicName).Inc()
+			log.ErrorF("error unmarshaling event: %v", err)
+			return
 		}
 
-		EventModel := repository.GoodModelToEvent(goodModel)
-		err = listen.eventsRepository.Create(EventModel)
+		EventModel := repository.GoodEventToEvent(event)
+		err := listen.eventsRepository.Create(ctx, EventModel)
 		if err != nil {
-			listen.logger.Error(err)
+			metrics.NatsConsumeErrorsTotal.WithLabelValues(EventTopicName).Inc()
+			log.Error(err)
 		}
 	})
 	if err != nil {
-		listen.logger.ErrorF("Could not subscribe to topic %s: %w", EventTopicName, err)
+		return fmt.Errorf("could not subscribe to topic %s: %w", EventTopicName, err)
 	}
 
-	go func() {
-		<-listen.ctx.Done()
-		listen.logger.Info("Stop listen events!")
-		if err := unsub(); err != nil {
-			panic(err)
+	listen.unsub = unsub
+	go listen.flushPeriodically()
+	listen.logger.Info("Event Listener started!")
+
+	return nil
+}
+
+// flushPeriodically записывает неполную пачку по таймеру, чтобы события не задерживались при низкой нагрузке.
+// Новый интервал из настроек подхватывается на ближайшем срабатывании таймера.
+func (listen *EventListener) flushPeriodically() {
+	defer close(listen.flushDone)
+
+	interval := listen.batch.FlushInterval()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if err := listen.eventsRepository.Flush(listen.ctx); err != nil {
+				listen.logger.ErrorF("error flushing events: %v", err)
+			}
+			if next := listen.batch.FlushInterval(); next != interval {
+				interval = next
+				ticker.Reset(interval)
+			}
+		case <-listen.stopFlush:
+			return
 		}
-	}()
+	}
+}
+
+// Stop отписывается от топика и записывает в Clickhouse накопленную пачку событий.
+// Пачка записывается и после истечения ctx на отписке, иначе накопленные события потеряются.
+func (listen *EventListener) Stop(ctx context.Context) error {
+	listen.logger.Info("Stop listen events!")
+
+	var errs []error
+	if listen.unsub != nil {
+		if err := listen.unsub(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("error on unsubscribe: %w", err))
+		}
+		close(listen.stopFlush)
+		<-listen.flushDone
+	}
+
+	if ctx.Err() != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.WithoutCancel(ctx), stopFlushTimeout)
+		defer cancel()
+	}
+
+	if err := listen.eventsRepository.Flush(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("error flushing events: %w", err))
+	}
+
+	return errors.Join(errs...)
 }
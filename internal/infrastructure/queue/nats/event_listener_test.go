@@ -0,0 +1,95 @@
+package nats_client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	"rest_clickhouse/pkg/logger/zerolog"
+	"rest_clickhouse/pkg/metrics"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// fakeSubscriber сохраняет обработчик подписки, чтобы тест передавал сообщения напрямую.
+type fakeSubscriber struct {
+	handler func(m *nats.Msg)
+}
+
+func (s *fakeSubscriber) Sub(_ string, fn func(m *nats.Msg)) (func(ctx context.Context) error, error) {
+	s.handler = fn
+	return func(context.Context) error { return nil }, nil
+}
+
+type fakeEventsRepository struct {
+	repository.EventsRepository
+
+	mu     sync.Mutex
+	events []*repository.EventsModel
+}
+
+func (r *fakeEventsRepository) Create(_ context.Context, event *repository.EventsModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+	return nil
+}
+
+func (r *fakeEventsRepository) Flush(context.Context) error {
+	return nil
+}
+
+func newTestListener(t *testing.T) (*fakeSubscriber, *fakeEventsRepository) {
+	t.Helper()
+
+	log, err := zerolog.NewZeroLog(io.Discard, zerolog.Config{})
+	if err != nil {
+		t.Fatalf("error creating logger: %v", err)
+	}
+	sub := &fakeSubscriber{}
+	events := &fakeEventsRepository{}
+	listener := NewEventListener(context.Background(), sub, events, repository.NewEventsBatchSettings(10, time.Hour), log)
+	if err := listener.ListenTopic(); err != nil {
+		t.Fatalf("error listening topic: %v", err)
+	}
+	t.Cleanup(func() { listener.Stop(context.Background()) })
+
+	return sub, events
+}
+
+func TestListenerDropsMalformedMessage(t *testing.T) {
+	sub, events := newTestListener(t)
+	consumeErrors := metrics.NatsConsumeErrorsTotal.WithLabelValues(EventTopicName)
+	before := testutil.ToFloat64(consumeErrors)
+
+	sub.handler(&nats.Msg{Subject: EventTopicName, Data: []byte("{not json")})
+
+	if len(events.events) != 0 {
+		t.Errorf("malformed message stored %d events, want none", len(events.events))
+	}
+	if got := testutil.ToFloat64(consumeErrors) - before; got != 1 {
+		t.Errorf("consume errors grew by %v, want 1", got)
+	}
+}
+
+func TestListenerStoresEvent(t *testing.T) {
+	sub, events := newTestListener(t)
+
+	data, err := json.Marshal(repository.GoodEvent{
+		GoodModel: repository.GoodModel{Id: 7, ProjectId: 1, Name: "chair"},
+		Type:      repository.GoodCreatedEvent,
+	})
+	if err != nil {
+		t.Fatalf("error marshaling event: %v", err)
+	}
+	sub.handler(&nats.Msg{Subject: EventTopicName, Data: data})
+
+	if len(events.events) != 1 || events.events[0].Id != 7 || events.events[0].EventType != repository.GoodCreatedEvent {
+		t.Errorf("stored events %+v, want created event of good 7", events.events)
+	}
+}
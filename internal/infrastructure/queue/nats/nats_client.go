@@ -1,34 +1,120 @@
 package nats_client
 
 import (
+	"context"
+	"fmt"
 	"rest_clickhouse/internal/infrastructure/queue"
+	"rest_clickhouse/pkg/metrics"
+	"rest_clickhouse/pkg/tracing"
+	"time"
 
 	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Nats реализует интерфейс PubSub для взаимодействия с NATS.
+// Компоненты работают с логическими топиками, subjects сопоставляет им subject NATS из конфигурации.
 type Nats struct {
-	Conn *nats.Conn
+	Conn     *nats.Conn
+	subjects map[string]string
 }
 
-func NewNatsClient(conn *nats.Conn) queue.PubSub {
-	return &Nats{Conn: conn}
+func NewNatsClient(conn *nats.Conn, subjects map[string]string) queue.PubSub {
+	return &Nats{Conn: conn, subjects: subjects}
+}
+
+func (n *Nats) subject(topic string) string {
+	if subject, ok := n.subjects[topic]; ok {
+		return subject
+	}
+
+	return topic
 }
 
 // Pub публикует сообщение в указанный топик NATS.
-func (n *Nats) Pub(topic string, data []byte) error {
-	return n.Conn.Publish(topic, data)
+func (n *Nats) Pub(ctx context.Context, topic string, data []byte) error {
+	ctx, span := tracing.StartSpan(ctx, "nats.publish "+topic, trace.WithSpanKind(trace.SpanKindProducer))
+	defer span.End()
+
+	if err := ctx.Err(); err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	msg := nats.NewMsg(n.subject(topic))
+	msg.Data = data
+	tracing.InjectNatsHeader(ctx, msg.Header)
+
+	if err := n.Conn.PublishMsg(msg); err != nil {
+		tracing.RecordError(span, err)
+		metrics.NatsPublishErrorsTotal.WithLabelValues(topic).Inc()
+		return err
+	}
+
+	metrics.NatsPublishedTotal.WithLabelValues(topic).Inc()
+	return nil
 }
 
 // Sub подписывается на сообщения в указанном топике NATS и вызывает функцию обратного вызова для каждого полученного сообщения.
 // Возвращает функцию для отписки от топика и ошибку, если подписка не удалась.
-func (n *Nats) Sub(topic string, fn func(m *nats.Msg)) (unsub func() error, err error) {
-	sub, err := n.Conn.Subscribe(topic, func(msg *nats.Msg) {
+func (n *Nats) Sub(topic string, fn func(m *nats.Msg)) (unsub func(ctx context.Context) error, err error) {
+	sub, err := n.Conn.Subscribe(n.subject(topic), func(msg *nats.Msg) {
+		metrics.NatsConsumedTotal.WithLabelValues(topic).Inc()
 		fn(msg)
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	return sub.Unsubscribe, nil
+	// Отписка дожидается обработки уже полученных сообщений, чтобы они не терялись при остановке.
+	// Если ctx истекает раньше, подписка снимается сразу.
+	unsub = func(ctx context.Context) error {
+		if err := sub.Drain(); err != nil {
+			return err
+		}
+
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for sub.IsValid() {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				_ = sub.Unsubscribe()
+				return ctx.Err()
+			}
+		}
+		return nil
+	}
+
+	return unsub, nil
+}
+
+// Ping проверяет, что соединение с NATS установлено и сервер отвечает.
+func (n *Nats) Ping(ctx context.Context) error {
+	if status := n.Conn.Status(); status != nats.CONNECTED {
+		return fmt.Errorf("nats connection is %s", status)
+	}
+
+	return n.Conn.FlushWithContext(ctx)
+}
+
+// Drain дожидается обработки полученных сообщений и отправки опубликованных, после чего закрывает соединение.
+// Если ctx истекает раньше, соединение закрывается сразу.
+func (n *Nats) Drain(ctx context.Context) error {
+	if err := n.Conn.Drain(); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for !n.Conn.IsClosed() {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			n.Conn.Close()
+			return ctx.Err()
+		}
+	}
+
+	return nil
 }
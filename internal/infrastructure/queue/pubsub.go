@@ -1,19 +1,37 @@
 package queue
 
-import "github.com/nats-io/nats.go"
+import (
+	"context"
+
+	"github.com/nats-io/nats.go"
+)
 
 // Publisher определяет интерфейс для публикации сообщений.
+// Контекст трейса передается подписчикам в заголовках сообщения.
 type Publisher interface {
-	Pub(topic string, data []byte) error
+	Pub(ctx context.Context, topic string, data []byte) error
 }
 
 // Subscriber определяет интерфейс для подписки на сообщения.
+// unsub дожидается обработки полученных сообщений, но не дольше ctx.
 type Subscriber interface {
-	Sub(topic string, fn func(m *nats.Msg)) (unsub func() error, err error)
+	Sub(topic string, fn func(m *nats.Msg)) (unsub func(ctx context.Context) error, err error)
+}
+
+// Pinger проверяет доступность брокера.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Drainer завершает работу с брокером, не теряя сообщения в полете.
+type Drainer interface {
+	Drain(ctx context.Context) error
 }
 
-// PubSub объединяет интерфейсы Publisher и Subscriber.
+// PubSub объединяет интерфейсы Publisher, Subscriber, Pinger и Drainer.
 type PubSub interface {
 	Publisher
 	Subscriber
+	Pinger
+	Drainer
 }
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	postgres "rest_clickhouse/pkg/db"
+	"rest_clickhouse/pkg/logger"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const apiKeyColumns = "id, project_id, name, prefix, key_hash, role, created_at, last_used_at, revoked_at"
+
+type ApiKeysRepository struct {
+	db     *postgres.DB
+	logger logger.Logger
+}
+
+func NewApiKeysRepository(db *postgres.DB, logger logger.Logger) repository.ApiKeysRepository {
+	return &ApiKeysRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *ApiKeysRepository) Create(ctx context.Context, apiKey *repository.ApiKeyModel) (*repository.ApiKeyModel, error) {
+	r.logger.WithContext(ctx).Sampled().Info("create api key")
+
+	var isProjectExist bool
+	if err := r.db.QueryRow(ctx, "SELECT EXISTS (SELECT id FROM projects WHERE id = $1)", apiKey.ProjectId).Scan(&isProjectExist); err != nil {
+		return nil, fmt.Errorf("error checking project existence: %w", err)
+	}
+	if !isProjectExist {
+		return nil, ErrProjectNotExist
+	}
+
+	createdApiKey := *apiKey
+	q := "INSERT INTO api_keys (project_id, name, prefix, key_hash, role) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at"
+	err := r.db.QueryRow(ctx, q,
+		apiKey.ProjectId,
+		apiKey.Name,
+		apiKey.Prefix,
+		apiKey.Hash,
+		apiKey.Role,
+	).Scan(&createdApiKey.Id, &createdApiKey.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("error on create api key: %w", err)
+	}
+
+	return &createdApiKey, nil
+}
+
+func (r *ApiKeysRepository) Get(ctx context.Context, id, projectId int) (*repository.ApiKeyModel, error) {
+	rows, err := r.db.Query(ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE id = $1 AND project_id = $2", id, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("error on get api key: %w", err)
+	}
+
+	return firstApiKey(rows)
+}
+
+func (r *ApiKeysRepository) GetByPrefix(ctx context.Context, prefix string) (*repository.ApiKeyModel, error) {
+	rows, err := r.db.Query(ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE prefix = $1", prefix)
+	if err != nil {
+		return nil, fmt.Errorf("error on get api key: %w", err)
+	}
+
+	return firstApiKey(rows)
+}
+
+func (r *ApiKeysRepository) GetList(ctx context.Context, projectId int) ([]*repository.ApiKeyModel, error) {
+	r.logger.WithContext(ctx).Sampled().Info("get api keys")
+
+	rows, err := r.db.Query(ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE project_id = $1 ORDER BY id", projectId)
+	if err != nil {
+		return nil, fmt.Errorf("error on get api keys: %w", err)
+	}
+
+	return scanApiKeys(rows)
+}
+
+// Revoke отзывает ключ, повторный отзыв не меняет время отзыва.
+func (r *ApiKeysRepository) Revoke(ctx context.Context, id, projectId int) (*repository.ApiKeyModel, error) {
+	r.logger.WithContext(ctx).Sampled().Info("revoke api key")
+
+	q := "UPDATE api_keys SET revoked_at = COALESCE(revoked_at, CURRENT_TIMESTAMP) WHERE id = $1 AND project_id = $2 RETURNING " + apiKeyColumns
+	rows, err := r.db.Query(ctx, q, id, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("error on revoke api key: %w", err)
+	}
+
+	return firstApiKey(rows)
+}
+
+// Rotate заменяет ключ, сохраняя его id, имя и роль. Отозванный ключ ротировать нельзя.
+func (r *ApiKeysRepository) Rotate(ctx context.Context, id, projectId int, prefix, hash string) (*repository.ApiKeyModel, error) {
+	r.logger.WithContext(ctx).Sampled().Info("rotate api key")
+
+	q := "UPDATE api_keys SET prefix = $3, key_hash = $4, last_used_at = NULL WHERE id = $1 AND project_id = $2 AND revoked_at IS NULL RETURNING " + apiKeyColumns
+	rows, err := r.db.Query(ctx, q, id, projectId, prefix, hash)
+	if err != nil {
+		return nil, fmt.Errorf("error on rotate api key: %w", err)
+	}
+
+	return firstApiKey(rows)
+}
+
+func (r *ApiKeysRepository) UpdateLastUsed(ctx context.Context, lastUsed map[int]time.Time) error {
+	ids := make([]int, 0, len(lastUsed))
+	usedAt := make([]time.Time, 0, len(lastUsed))
+	for id, at := range lastUsed {
+		ids = append(ids, id)
+		usedAt = append(usedAt, at)
+	}
+
+	q := `UPDATE api_keys AS k SET last_used_at = u.used_at
+		FROM unnest($1::int[], $2::timestamp[]) AS u(id, used_at)
+		WHERE k.id = u.id AND (k.last_used_at IS NULL OR k.last_used_at < u.used_at)`
+	if _, err := r.db.Exec(ctx, q, ids, usedAt); err != nil {
+		return fmt.Errorf("error on update api keys last used: %w", err)
+	}
+
+	return nil
+}
+
+func firstApiKey(rows pgx.Rows) (*repository.ApiKeyModel, error) {
+	apiKeys, err := scanApiKeys(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(apiKeys) == 0 {
+		return nil, repository.ErrApiKeyNotExist
+	}
+
+	return apiKeys[0], nil
+}
+
+func scanApiKeys(rows pgx.Rows) ([]*repository.ApiKeyModel, error) {
+	defer rows.Close()
+
+	apiKeyModels := make([]*repository.ApiKeyModel, 0)
+	for rows.Next() {
+		apiKeyModel := new(repository.ApiKeyModel)
+		err := rows.Scan(
+			&apiKeyModel.Id,
+			&apiKeyModel.ProjectId,
+			&apiKeyModel.Name,
+			&apiKeyModel.Prefix,
+			&apiKeyModel.Hash,
+			&apiKeyModel.Role,
+			&apiKeyModel.CreatedAt,
+			&apiKeyModel.LastUsedAt,
+			&apiKeyModel.RevokedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning results: %w", err)
+		}
+		apiKeyModels = append(apiKeyModels, apiKeyModel)
+	}
+
+	return apiKeyModels, rows.Err()
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"rest_clickhouse/internal/apperrors"
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	postgres "rest_clickhouse/pkg/db"
+	"rest_clickhouse/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrAttributeNotExist     = apperrors.AttributeNotFound
+	ErrAttributeAlreadyExist = apperrors.AttributeAlreadyExist
+)
+
+const uniqueViolationCode = "23505"
+
+type AttributesRepository struct {
+	db     *postgres.DB
+	logger logger.Logger
+}
+
+func NewAttributesRepository(db *postgres.DB, logger logger.Logger) repository.AttributesRepository {
+	return &AttributesRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *AttributesRepository) Create(ctx context.Context, attribute *repository.AttributeModel) (*repository.AttributeModel, error) {
+	r.logger.WithContext(ctx).Sampled().Info("create attribute")
+
+	var isProjectExist bool
+	if err := r.db.QueryRow(ctx, "SELECT EXISTS (SELECT id FROM projects WHERE id = $1)", attribute.ProjectId).Scan(&isProjectExist); err != nil {
+		return nil, fmt.Errorf("error checking project existence: %w", err)
+	}
+	if !isProjectExist {
+		return nil, ErrProjectNotExist
+	}
+
+	q := "INSERT INTO attribute_schemas (project_id, name, type, required, enum_values) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at"
+
+	createdAttribute := *attribute
+	if createdAttribute.EnumValues == nil {
+		createdAttribute.EnumValues = []string{}
+	}
+
+	err := r.db.QueryRow(ctx, q,
+		attribute.ProjectId,
+		attribute.Name,
+		attribute.Type,
+		attribute.Required,
+		createdAttribute.EnumValues,
+	).Scan(&createdAttribute.Id, &createdAttribute.CreatedAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+		return nil, ErrAttributeAlreadyExist
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("error on create attribute: %w", err)
+	}
+
+	return &createdAttribute, nil
+}
+
+func (r *AttributesRepository) GetList(ctx context.Context, projectId int) ([]*repository.AttributeModel, error) {
+	r.logger.WithContext(ctx).Sampled().Info("get attributes")
+
+	rows, err := r.db.Query(ctx, "SELECT id, project_id, name, type, required, enum_values, created_at FROM attribute_schemas WHERE project_id = $1 ORDER BY name", projectId)
+	if err != nil {
+		return nil, fmt.Errorf("error on get attributes: %w", err)
+	}
+	defer rows.Close()
+
+	attributeModels := make([]*repository.AttributeModel, 0)
+	for rows.Next() {
+		attributeModel := new(repository.AttributeModel)
+		err = rows.Scan(
+			&attributeModel.Id,
+			&attributeModel.ProjectId,
+			&attributeModel.Name,
+			&attributeModel.Type,
+			&attributeModel.Required,
+			&attributeModel.EnumValues,
+			&attributeModel.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning results: %w", err)
+		}
+		attributeModels = append(attributeModels, attributeModel)
+	}
+
+	return attributeModels, rows.Err()
+}
+
+// Remove удаляет атрибут из схемы проекта и его значения из товаров.
+func (r *AttributesRepository) Remove(ctx context.Context, name string, projectId int) error {
+	r.logger.WithContext(ctx).Sampled().Info("remove attribute")
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			r.logger.WithContext(ctx).ErrorF("rollback error")
+		}
+	}()
+
+	tag, err := tx.Exec(ctx, "DELETE FROM attribute_schemas WHERE name = $1 AND project_id = $2", name, projectId)
+	if err != nil {
+		return fmt.Errorf("error on remove attribute: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrAttributeNotExist
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE goods SET attributes = attributes - $1::text WHERE project_id = $2 AND attributes ? $1", name, projectId); err != nil {
+		return fmt.Errorf("error on remove attribute values: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error on commit: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	"rest_clickhouse/pkg/logger"
+	"strconv"
+	"strings"
+)
+
+type AuditRepository struct {
+	clickHouseConn *sql.DB
+	logger         logger.Logger
+}
+
+func NewAuditRepository(clickHouseConn *sql.DB, logger logger.Logger) repository.AuditRepository {
+	return &AuditRepository{
+		clickHouseConn: clickHouseConn,
+		logger:         logger,
+	}
+}
+
+// GetList читает журнал из таблицы events. Записи до появления колонок аудита имеют пустого автора.
+func (r *AuditRepository) GetList(ctx context.Context, filter repository.AuditFilter) ([]*repository.AuditRecordModel, error) {
+	conditions := make([]string, 0, 4)
+	args := make([]interface{}, 0, 6)
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, strings.ReplaceAll(condition, "?", "$"+strconv.Itoa(len(args))))
+	}
+
+	if filter.Actor != "" {
+		where("actor_id = ?", filter.Actor)
+	}
+	if filter.ProjectId != 0 {
+		where("project_id = ?", filter.ProjectId)
+	}
+	if !filter.From.IsZero() {
+		where("EventTime >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		where("EventTime < ?", filter.To)
+	}
+
+	q := `SELECT id, project_id, name, event_type, removed, actor_id, auth_method, client_ip, user_agent, request_id, EventTime
+		FROM events`
+	if len(conditions) > 0 {
+		q += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	args = append(args, filter.Limit, filter.Offset)
+	q += fmt.Sprintf(" ORDER BY EventTime DESC LIMIT $%d OFFSET $%d", len(args)-1, len(args))
+
+	rows, err := r.clickHouseConn.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error on get audit records: %w", err)
+	}
+	defer rows.Close()
+
+	records := make([]*repository.AuditRecordModel, 0)
+	for rows.Next() {
+		record := new(repository.AuditRecordModel)
+		err := rows.Scan(
+			&record.Id,
+			&record.ProjectId,
+			&record.Name,
+			&record.EventType,
+			&record.Removed,
+			&record.Actor,
+			&record.AuthMethod,
+			&record.ClientIP,
+			&record.UserAgent,
+			&record.RequestId,
+			&record.EventTime,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning results: %w", err)
+		}
+		records = append(records, record)
+	}
+
+	return records, rows.Err()
+}
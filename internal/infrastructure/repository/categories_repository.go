@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	postgres "rest_clickhouse/pkg/db"
+	"rest_clickhouse/pkg/logger"
+)
+
+type CategoriesRepository struct {
+	db     *postgres.DB
+	logger logger.Logger
+}
+
+func NewCategoriesRepository(db *postgres.DB, logger logger.Logger) repository.CategoriesRepository {
+	return &CategoriesRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *CategoriesRepository) Create(ctx context.Context, category *repository.CategoryModel) (*repository.CategoryModel, error) {
+	r.logger.WithContext(ctx).Sampled().Info("create category")
+
+	var isProjectExist bool
+	if err := r.db.QueryRow(ctx, "SELECT EXISTS (SELECT id FROM projects WHERE id = $1)", category.ProjectId).Scan(&isProjectExist); err != nil {
+		return nil, fmt.Errorf("error checking project existence: %w", err)
+	}
+	if !isProjectExist {
+		return nil, ErrProjectNotExist
+	}
+
+	if category.ParentId != nil {
+		var isParentExist bool
+		err := r.db.QueryRow(ctx, "SELECT EXISTS (SELECT id FROM categories WHERE id = $1 AND project_id = $2)", *category.ParentId, category.ProjectId).Scan(&isParentExist)
+		if err != nil {
+			return nil, fmt.Errorf("error checking parent category existence: %w", err)
+		}
+		if !isParentExist {
+			return nil, ErrCategoryNotExist
+		}
+	}
+
+	q := "INSERT INTO categories (project_id, parent_id, name) VALUES ($1, $2, $3) RETURNING id, created_at"
+
+	createdCategory := *category
+	err := r.db.QueryRow(ctx, q, category.ProjectId, category.ParentId, category.Name).Scan(&createdCategory.Id, &createdCategory.CreatedAt)
+	if err != nil {
+		r.logger.WithContext(ctx).ErrorF("error on create category: %v", err)
+		return nil, fmt.Errorf("error on create category: %w", err)
+	}
+
+	return &createdCategory, nil
+}
+
+func (r *CategoriesRepository) GetList(ctx context.Context, projectId int) ([]*repository.CategoryModel, error) {
+	r.logger.WithContext(ctx).Sampled().Info("get categories")
+
+	rows, err := r.db.Query(ctx, "SELECT id, project_id, parent_id, name, created_at FROM categories WHERE project_id = $1 ORDER BY name, id", projectId)
+	if err != nil {
+		return nil, fmt.Errorf("error on get categories: %w", err)
+	}
+	defer rows.Close()
+
+	categoryModels := make([]*repository.CategoryModel, 0)
+	for rows.Next() {
+		categoryModel := new(repository.CategoryModel)
+		err = rows.Scan(
+			&categoryModel.Id,
+			&categoryModel.ProjectId,
+			&categoryModel.ParentId,
+			&categoryModel.Name,
+			&categoryModel.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning results: %w", err)
+		}
+		categoryModels = append(categoryModels, categoryModel)
+	}
+
+	return categoryModels, rows.Err()
+}
+
+// Remove удаляет категорию вместе с подкатегориями, товары остаются без категории.
+func (r *CategoriesRepository) Remove(ctx context.Context, id, projectId int) error {
+	r.logger.WithContext(ctx).Sampled().Info("remove category")
+
+	tag, err := r.db.Exec(ctx, "DELETE FROM categories WHERE id = $1 AND project_id = $2", id, projectId)
+	if err != nil {
+		return fmt.Errorf("error on remove category: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrCategoryNotExist
+	}
+
+	return nil
+}
@@ -7,31 +7,138 @@ import (
 	"fmt"
 	"rest_clickhouse/internal/infrastructure/usecase/repository"
 	"rest_clickhouse/pkg/logger"
-)
+	"rest_clickhouse/pkg/metrics"
+	"rest_clickhouse/pkg/tracing"
+	"sync"
+	"time"
 
-const eventsPackCount = 100
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
 
 type EventsRepository struct {
 	clickHouseConn *sql.DB
+	batch          *repository.EventsBatchSettings
 	eventModels    []*repository.EventsModel
+	spanLinks      []trace.Link
+	mu             sync.Mutex
 	logger         logger.Logger
 }
 
-func NewLogsRepository(clickHouseConn *sql.DB, logger logger.Logger) repository.EventsRepository {
+func NewLogsRepository(clickHouseConn *sql.DB, batch *repository.EventsBatchSettings, logger logger.Logger) repository.EventsRepository {
 	return &EventsRepository{
 		clickHouseConn: clickHouseConn,
+		batch:          batch,
 		eventModels:    make([]*repository.EventsModel, 0),
 		logger:         logger,
 	}
 }
 
-func (r *EventsRepository) Create(eventModel *repository.EventsModel) error {
-	if len(r.eventModels) < eventsPackCount {
-		r.eventModels = append(r.eventModels, eventModel)
+func (r *EventsRepository) Create(ctx context.Context, eventModel *repository.EventsModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.eventModels = append(r.eventModels, eventModel)
+	// Вставка идет пачкой, поэтому спан вставки связывается со всеми трейсами пачки.
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		r.spanLinks = append(r.spanLinks, trace.Link{SpanContext: spanContext})
+	}
+
+	if len(r.eventModels) < r.batch.Size() {
+		return nil
+	}
+
+	return r.flushBuffered(ctx)
+}
+
+// Flush записывает накопленные события, не дожидаясь заполнения пачки. Вызывается при остановке.
+func (r *EventsRepository) Flush(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.eventModels) == 0 {
 		return nil
 	}
 
-	ctx := context.Background()
+	return r.flushBuffered(ctx)
+}
+
+// GetHistory читает последние события товаров или, если товары не заданы, проектов.
+// LIMIT BY ограничивает число событий на каждый товар или проект в одном запросе.
+func (r *EventsRepository) GetHistory(ctx context.Context, filter repository.EventsHistoryFilter) ([]*repository.EventsModel, error) {
+	column, ids := "id", filter.GoodIds
+	if len(ids) == 0 {
+		column, ids = "project_id", filter.ProjectIds
+	}
+
+	q := fmt.Sprintf(`SELECT id, project_id, name, description, priority, removed, tags, attributes,
+		event_type, actor_id, auth_method, client_ip, user_agent, request_id, EventTime
+		FROM events WHERE has($1, %[1]s)
+		ORDER BY EventTime DESC LIMIT $2 BY %[1]s`, column)
+
+	rows, err := r.clickHouseConn.QueryContext(ctx, q, ids, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("error on get events history: %w", err)
+	}
+	defer rows.Close()
+
+	events := make([]*repository.EventsModel, 0)
+	for rows.Next() {
+		event := new(repository.EventsModel)
+		err := rows.Scan(
+			&event.Id,
+			&event.ProjectId,
+			&event.Name,
+			&event.Description,
+			&event.Priority,
+			&event.Removed,
+			&event.Tags,
+			&event.Attributes,
+			&event.EventType,
+			&event.Audit.Actor,
+			&event.Audit.AuthMethod,
+			&event.Audit.ClientIP,
+			&event.Audit.UserAgent,
+			&event.Audit.RequestId,
+			&event.EventTime,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning results: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+func (r *EventsRepository) flushBuffered(ctx context.Context) error {
+	start := time.Now()
+	if err := r.flush(ctx); err != nil {
+		metrics.ClickhouseFlushErrorsTotal.Inc()
+		return err
+	}
+
+	metrics.ClickhouseBatchSize.Observe(float64(len(r.eventModels)))
+	metrics.ClickhouseFlushDuration.Observe(time.Since(start).Seconds())
+
+	// Очищаем список eventModels после успешного коммита.
+	r.eventModels = r.eventModels[:0]
+	r.spanLinks = r.spanLinks[:0]
+
+	return nil
+}
+
+func (r *EventsRepository) flush(ctx context.Context) (err error) {
+	ctx, span := tracing.StartSpan(ctx, "clickhouse.insert events",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithLinks(r.spanLinks...),
+		trace.WithAttributes(attribute.Int("events.count", len(r.eventModels))),
+	)
+	defer func() {
+		tracing.RecordError(span, err)
+		span.End()
+	}()
+
 	tx, err := r.clickHouseConn.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("error beginning transaction: %w", err)
@@ -39,13 +146,16 @@ func (r *EventsRepository) Create(eventModel *repository.EventsModel) error {
 
 	defer func() {
 		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
-			r.logger.ErrorF("rollback error: %v", err)
+			r.logger.WithContext(ctx).ErrorF("rollback error: %v", err)
 		}
 	}()
 
-	query := "INSERT INTO Events (Id,ProjectId,Name,Description,Priority,Removed,EventTime) values ($1, $2,$3,$4,$5,$6,$7)"
+	query := `INSERT INTO events (id, project_id, name, description, priority, removed, tags, attributes,
+		event_type, actor_id, auth_method, client_ip, user_agent, request_id, EventTime)
+		values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)`
 	for _, event := range r.eventModels {
-		_, err = tx.Exec(
+		_, err = tx.ExecContext(
+			ctx,
 			query,
 			event.Id,
 			event.ProjectId,
@@ -53,6 +163,14 @@ func (r *EventsRepository) Create(eventModel *repository.EventsModel) error {
 			event.Description,
 			event.Priority,
 			event.Removed,
+			event.Tags,
+			event.Attributes,
+			event.EventType,
+			event.Audit.Actor,
+			event.Audit.AuthMethod,
+			event.Audit.ClientIP,
+			event.Audit.UserAgent,
+			event.Audit.RequestId,
 			event.EventTime)
 		if err != nil {
 			return fmt.Errorf("error executing query: %w", err)
@@ -63,8 +181,5 @@ func (r *EventsRepository) Create(eventModel *repository.EventsModel) error {
 		return fmt.Errorf("error committing transaction: %w", err)
 	}
 
-	// Очищаем список eventModels после успешного коммита.
-	r.eventModels = r.eventModels[:0]
-
 	return nil
 }
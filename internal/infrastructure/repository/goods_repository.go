@@ -4,22 +4,40 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"html"
+	"rest_clickhouse/internal/apperrors"
 	"rest_clickhouse/internal/infrastructure/usecase/repository"
 	postgres "rest_clickhouse/pkg/db"
 	"rest_clickhouse/pkg/logger"
+	"strings"
 	"sync"
 
 	"github.com/go-redis/redis"
+	"github.com/jackc/pgx/v5"
 )
 
 var (
-	ErrGoodNotExist    = errors.New("good not exist")
-	ErrProjectNotExist = errors.New("project not exist")
-	ErrOnUpdateGood    = errors.New("error when update good")
+	ErrGoodNotExist     = apperrors.GoodNotFound
+	ErrProjectNotExist  = apperrors.ProjectNotFound
+	ErrCategoryNotExist = apperrors.CategoryNotFound
 )
 
 const redisGoodPostfix = "good"
 
+// highlightStart и highlightStop отмечают совпадения в ts_headline. Это символы из области частного
+// использования Unicode: текст товара экранируется уже после подсветки, и метки заменяются на теги <b>.
+const (
+	highlightStart = "\uE000"
+	highlightStop  = "\uE001"
+)
+
+var highlightReplacer = strings.NewReplacer(highlightStart, "<b>", highlightStop, "</b>")
+
+// goodColumns перечисляет колонки товара вместе с категорией и отсортированными тегами.
+const goodColumns = `g.id, g.project_id, g.name, g.description, g.priority, g.removed, g.created_at, g.category_id, g.attributes,
+	COALESCE((SELECT array_agg(t.name ORDER BY t.name) FROM goods_tags gt JOIN tags t ON t.id = gt.tag_id
+		WHERE gt.good_id = g.id AND gt.project_id = g.project_id), '{}')`
+
 type GoodsRepository struct {
 	db          *postgres.DB
 	redisClient *redis.Client
@@ -36,13 +54,18 @@ func NewGoodsRepository(ctx context.Context, db *postgres.DB, redisClient *redis
 }
 
 func (r *GoodsRepository) Create(ctx context.Context, good *repository.GoodModel) (*repository.GoodModel, error) {
-	r.logger.Info("create good")
-	q := "INSERT INTO goods (project_id, name, description, priority, removed) VALUES ($1, $2, $3, $4, $5) RETURNING id"
+	r.logger.WithContext(ctx).Sampled().Info("create good")
+	q := "INSERT INTO goods (project_id, name, description, priority, removed, attributes) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id"
+
+	attributes := good.Attributes
+	if attributes == nil {
+		attributes = map[string]interface{}{}
+	}
 
 	var id int
-	err := r.db.QueryRow(ctx, q, good.ProjectId, good.Name, good.Description, good.Priority, good.Removed).Scan(&id)
+	err := r.db.QueryRow(ctx, q, good.ProjectId, good.Name, good.Description, good.Priority, good.Removed, attributes).Scan(&id)
 	if err != nil {
-		r.logger.ErrorF("error on create good: %v", err)
+		r.logger.WithContext(ctx).ErrorF("error on create good: %v", err)
 		return nil, fmt.Errorf("error on create good: %w", err)
 	}
 
@@ -54,31 +77,64 @@ func (r *GoodsRepository) Create(ctx context.Context, good *repository.GoodModel
 	return createdGood, nil
 }
 
-func (r *GoodsRepository) GetList(ctx context.Context, limit, offset int) (*repository.GoodModelList, error) {
-	r.logger.Info("get goods")
+func (r *GoodsRepository) Get(ctx context.Context, id, projectId int) (*repository.GoodModel, error) {
+	r.logger.WithContext(ctx).Sampled().Info("get good")
+
+	rows, err := r.db.Query(ctx, "SELECT "+goodColumns+" FROM goods g WHERE g.id = $1 AND g.project_id = $2", id, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("error on get good: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error on get good: %w", err)
+		}
+		return nil, ErrGoodNotExist
+	}
+
+	goodModel, err := scanGood(rows)
+	if err != nil {
+		return nil, fmt.Errorf("error scanning results: %w", err)
+	}
+
+	return goodModel, nil
+}
+
+func (r *GoodsRepository) GetList(ctx context.Context, limit, offset int, filter repository.GoodListFilter) (*repository.GoodModelList, error) {
+	r.logger.WithContext(ctx).Sampled().Info("get goods")
 
 	goodListModels := &repository.GoodModelList{}
 	goodModels := make([]*repository.GoodModel, 0)
 
-	goodsQuery := "SELECT id, project_id, name, description, priority, removed, created_at FROM goods ORDER BY id OFFSET $1 LIMIT COALESCE(NULLIF($2, 0), 10)"
+	goodsQuery := `SELECT ` + goodColumns + ` FROM goods g
+		WHERE ($3 = '' OR EXISTS (
+			SELECT 1 FROM goods_tags gt JOIN tags t ON t.id = gt.tag_id
+			WHERE gt.good_id = g.id AND gt.project_id = g.project_id AND t.name = $3))
+		AND ($4 = 0 OR g.category_id IN (
+			WITH RECURSIVE sub AS (
+				SELECT id FROM categories WHERE id = $4
+				UNION ALL
+				SELECT c.id FROM categories c JOIN sub ON c.parent_id = sub.id)
+			SELECT id FROM sub))
+		AND ($5::jsonb IS NULL OR g.attributes @> $5::jsonb)
+		AND ($6 = 0 OR g.project_id = $6)
+		ORDER BY g.id OFFSET $1 LIMIT COALESCE(NULLIF($2, 0), 10)`
+
+	var attributesFilter map[string]interface{}
+	if len(filter.Attributes) > 0 {
+		attributesFilter = filter.Attributes
+	}
 
-	rows, err := r.db.Query(ctx, goodsQuery, offset, limit)
+	rows, err := r.db.Query(ctx, goodsQuery, offset, limit, filter.Tag, filter.CategoryId, attributesFilter, filter.ProjectId)
 
 	if err != nil {
 		return goodListModels, err
 	}
+	defer rows.Close()
 
 	for rows.Next() {
-		goodModel := new(repository.GoodModel)
-		err = rows.Scan(
-			&goodModel.Id,
-			&goodModel.ProjectId,
-			&goodModel.Name,
-			&goodModel.Description,
-			&goodModel.Priority,
-			&goodModel.Removed,
-			&goodModel.CreatedAt,
-		)
+		goodModel, err := scanGood(rows)
 		if err != nil {
 			return nil, fmt.Errorf("error scanning results: %w", err)
 		}
@@ -97,8 +153,70 @@ func (r *GoodsRepository) GetList(ctx context.Context, limit, offset int) (*repo
 	return goodListModels, nil
 }
 
+// GetListByProjects одним запросом выбирает страницу товаров каждого проекта: строки нумеруются внутри проекта.
+func (r *GoodsRepository) GetListByProjects(ctx context.Context, query repository.ProjectGoodsQuery) (map[int][]*repository.GoodModel, error) {
+	r.logger.WithContext(ctx).Sampled().Info("get goods by projects")
+
+	goodsQuery := `SELECT ` + goodColumns + ` FROM (
+			SELECT g.*, row_number() OVER (PARTITION BY g.project_id ORDER BY g.id) AS rn
+			FROM goods g
+			WHERE g.project_id = ANY($1)
+			AND ($2 = '' OR EXISTS (
+				SELECT 1 FROM goods_tags gt JOIN tags t ON t.id = gt.tag_id
+				WHERE gt.good_id = g.id AND gt.project_id = g.project_id AND t.name = $2))
+			AND ($3 = 0 OR g.category_id IN (
+				WITH RECURSIVE sub AS (
+					SELECT id FROM categories WHERE id = $3
+					UNION ALL
+					SELECT c.id FROM categories c JOIN sub ON c.parent_id = sub.id)
+				SELECT id FROM sub))
+		) g
+		WHERE g.rn > $4 AND g.rn <= $4 + $5
+		ORDER BY g.project_id, g.id`
+
+	rows, err := r.db.Query(ctx, goodsQuery, query.ProjectIds, query.Tag, query.CategoryId, query.Offset, query.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("error on get goods by projects: %w", err)
+	}
+	defer rows.Close()
+
+	goodModels := make(map[int][]*repository.GoodModel, len(query.ProjectIds))
+	for rows.Next() {
+		goodModel, err := scanGood(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning results: %w", err)
+		}
+		goodModels[goodModel.ProjectId] = append(goodModels[goodModel.ProjectId], goodModel)
+	}
+
+	return goodModels, rows.Err()
+}
+
+// CountByProjects считает товары проектов, проекта без товаров нет в результате.
+func (r *GoodsRepository) CountByProjects(ctx context.Context, projectIds []int) (map[int]*repository.GoodsCountModel, error) {
+	r.logger.WithContext(ctx).Sampled().Info("count goods by projects")
+
+	rows, err := r.db.Query(ctx, `SELECT project_id, count(*), count(*) FILTER (WHERE removed)
+		FROM goods WHERE project_id = ANY($1) GROUP BY project_id`, projectIds)
+	if err != nil {
+		return nil, fmt.Errorf("error on count goods: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int]*repository.GoodsCountModel, len(projectIds))
+	for rows.Next() {
+		count := new(repository.GoodsCountModel)
+		if err := rows.Scan(&count.ProjectId, &count.Total, &count.Removed); err != nil {
+			return nil, fmt.Errorf("error scanning results: %w", err)
+		}
+		counts[count.ProjectId] = count
+	}
+
+	return counts, rows.Err()
+}
+
 func (r *GoodsRepository) Remove(ctx context.Context, good *repository.GoodModel) (*repository.GoodModel, error) {
-	r.logger.Info("remove good")
+	r.logger.WithContext(ctx).Sampled().Info("remove good")
 
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
@@ -106,7 +224,7 @@ func (r *GoodsRepository) Remove(ctx context.Context, good *repository.GoodModel
 	}
 	defer func() {
 		if err := tx.Rollback(ctx); err != nil {
-			r.logger.ErrorF("rollback error")
+			r.logger.WithContext(ctx).ErrorF("rollback error")
 		}
 	}()
 
@@ -126,7 +244,7 @@ func (r *GoodsRepository) Remove(ctx context.Context, good *repository.GoodModel
 
 	_, err = tx.Exec(ctx, "UPDATE goods SET removed = $1 WHERE id = $2 AND project_id = $3", good.Removed, good.Id, good.ProjectId)
 	if err != nil {
-		return nil, ErrOnUpdateGood
+		return nil, fmt.Errorf("error updating good: %w", err)
 	}
 
 	if err := tx.Commit(ctx); err != nil {
@@ -141,45 +259,191 @@ func (r *GoodsRepository) Remove(ctx context.Context, good *repository.GoodModel
 	return updatedGood, nil
 }
 
-func (r *GoodsRepository) Update(ctx context.Context, good *repository.GoodModel) (*repository.GoodModel, error) {
-	r.logger.Info("update good")
+func (r *GoodsRepository) Update(ctx context.Context, update *repository.GoodUpdateModel) (*repository.GoodModel, error) {
+	r.logger.WithContext(ctx).Sampled().Info("update good")
 
-	exists, err := r.checkGoodExistence(ctx, good.Id, good.ProjectId)
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("error good with same Id exsists: %w", err)
+		return nil, fmt.Errorf("error begin transaction: %w", err)
 	}
-	if !exists {
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			r.logger.WithContext(ctx).ErrorF("rollback error")
+		}
+	}()
+
+	// Строка блокируется до конца транзакции: атрибуты сливаются с актуальным значением,
+	// а параллельное изменение того же товара ждет коммита.
+	var current map[string]interface{}
+	err = tx.QueryRow(ctx, "SELECT attributes FROM goods WHERE id = $1 AND project_id = $2 FOR UPDATE",
+		update.Id, update.ProjectId).Scan(&current)
+	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, ErrGoodNotExist
 	}
+	if err != nil {
+		return nil, fmt.Errorf("error locking good: %w", err)
+	}
+
+	attributes := update.Attributes
+	if update.MergeAttributes != nil {
+		if current == nil {
+			current = map[string]interface{}{}
+		}
+		attributes, err = update.MergeAttributes(current)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// SET собирается только из переданных полей, остальные колонки не меняются.
+	var sets []string
+	var args []interface{}
+	set := func(column string, value interface{}) {
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	if update.Name != nil {
+		set("name", *update.Name)
+	}
+	if update.Description != nil {
+		set("description", *update.Description)
+	}
+	if update.Priority != nil {
+		set("priority", *update.Priority)
+	}
+	if attributes != nil {
+		set("attributes", attributes)
+	}
+
+	if len(sets) == 0 {
+		return r.Get(ctx, update.Id, update.ProjectId)
+	}
+
+	args = append(args, update.Id, update.ProjectId)
+	updateQuery := fmt.Sprintf("UPDATE goods SET %s WHERE id = $%d AND project_id = $%d",
+		strings.Join(sets, ", "), len(args)-1, len(args))
+
+	if _, err := tx.Exec(ctx, updateQuery, args...); err != nil {
+		return nil, fmt.Errorf("error on update: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("error on commit: %w", err)
+	}
+
+	// Кэш сбрасывается после коммита, иначе параллельное чтение могло бы вернуть в него старое значение.
+	// Изменение уже закоммичено, поэтому ошибка сброса только логируется.
+	invalidateKey := fmt.Sprintf("%s-%d", redisGoodPostfix, update.Id)
+	if err := r.redisClient.WithContext(ctx).Del(invalidateKey).Err(); err != nil {
+		r.logger.WithContext(ctx).ErrorF("error invalidating key %s: %v", invalidateKey, err)
+	}
 
-	invalidateKey := fmt.Sprintf("%s-%d", redisGoodPostfix, good.Id)
-	if err := r.redisClient.Set(invalidateKey, good, 0).Err(); err != nil {
-		return nil, fmt.Errorf("error invalidating key: %w", err)
+	return r.getGoodById(ctx, update.Id)
+}
+
+func (r *GoodsRepository) Search(ctx context.Context, query repository.GoodSearchQuery) (*repository.GoodSearchModelList, error) {
+	r.logger.WithContext(ctx).Sampled().Info("search goods")
+
+	// Полнотекстовый поиск по search_vector, при опечатках срабатывает триграммное сравнение по имени.
+	searchQuery := `
+		WITH q AS (SELECT websearch_to_tsquery('simple', $1) AS query)
+		SELECT g.id, g.project_id, g.name, g.description, g.priority, g.removed, g.created_at,
+			GREATEST(ts_rank(g.search_vector, q.query), similarity(g.name, $1)) AS rank,
+			ts_headline('simple', translate(g.name, $5, ''), q.query, 'HighlightAll=true, StartSel=' || $6 || ', StopSel=' || $7),
+			ts_headline('simple', translate(g.description, $5, ''), q.query, 'MaxFragments=2, StartSel=' || $6 || ', StopSel=' || $7)
+		FROM goods g, q
+		WHERE g.removed = false
+			AND ($2 = 0 OR g.project_id = $2)
+			AND (g.search_vector @@ q.query OR g.name % $1)
+		ORDER BY rank DESC, g.priority ASC, g.id
+		OFFSET $3 LIMIT COALESCE(NULLIF($4, 0), 10)`
+
+	rows, err := r.db.Query(ctx, searchQuery, query.Query, query.ProjectId, query.Offset, query.Limit,
+		highlightStart+highlightStop, highlightStart, highlightStop)
+	if err != nil {
+		return nil, fmt.Errorf("error on search goods: %w", err)
 	}
+	defer rows.Close()
+
+	goodModels := make([]*repository.GoodSearchModel, 0)
+	for rows.Next() {
+		goodModel := new(repository.GoodSearchModel)
+		err = rows.Scan(
+			&goodModel.Id,
+			&goodModel.ProjectId,
+			&goodModel.Name,
+			&goodModel.Description,
+			&goodModel.Priority,
+			&goodModel.Removed,
+			&goodModel.CreatedAt,
+			&goodModel.Rank,
+			&goodModel.NameHighlight,
+			&goodModel.DescriptionHighlight,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning results: %w", err)
+		}
+		goodModel.NameHighlight = highlightHTML(goodModel.NameHighlight)
+		goodModel.DescriptionHighlight = highlightHTML(goodModel.DescriptionHighlight)
+		goodModels = append(goodModels, goodModel)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading results: %w", err)
+	}
+
+	return &repository.GoodSearchModelList{
+		Meta: repository.Meta{
+			Total:  len(goodModels),
+			Limit:  query.Limit,
+			Offset: query.Offset,
+		},
+		Goods: goodModels,
+	}, nil
+}
+
+// highlightHTML экранирует текст фрагмента и заменяет метки совпадений на теги <b>,
+// чтобы HTML из названия или описания товара не попал в разметку клиента.
+func highlightHTML(headline string) string {
+	return highlightReplacer.Replace(html.EscapeString(headline))
+}
+
+func (r *GoodsRepository) SetTags(ctx context.Context, good *repository.GoodModel) (*repository.GoodModel, error) {
+	r.logger.WithContext(ctx).Sampled().Info("set good tags")
 
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("error begin transaction: %w", err)
 	}
 	defer func() {
-		if err := tx.Rollback(ctx); err != nil {
-			r.logger.ErrorF("rollback error")
+		if err := tx.Rollback(ctx); err != nil && !errors.Is(err, pgx.ErrTxClosed) {
+			r.logger.WithContext(ctx).ErrorF("rollback error")
 		}
 	}()
 
-	if _, err := tx.Exec(ctx, "SET TRANSACTION ISOLATION LEVEL REPEATABLE READ"); err != nil {
-		return nil, err
+	var isGoodExist bool
+	err = tx.QueryRow(ctx, "SELECT EXISTS (SELECT id FROM goods WHERE id = $1 AND project_id = $2)", good.Id, good.ProjectId).Scan(&isGoodExist)
+	if err != nil {
+		return nil, fmt.Errorf("error checking good existence: %w", err)
+	}
+
+	if !isGoodExist {
+		return nil, ErrGoodNotExist
 	}
 
-	var updateQuery string
-	if good.Description != "" {
-		updateQuery = "UPDATE goods SET name = $1, description = $2 WHERE id = $3 AND project_id = $4"
-	} else {
-		updateQuery = "UPDATE goods SET name = $1 WHERE id = $2 AND project_id = $3"
+	if _, err := tx.Exec(ctx, "DELETE FROM goods_tags WHERE good_id = $1 AND project_id = $2", good.Id, good.ProjectId); err != nil {
+		return nil, fmt.Errorf("error clearing good tags: %w", err)
 	}
-	_, err = tx.Exec(ctx, updateQuery, good.Name, good.Description, good.Id, good.ProjectId)
-	if err != nil {
-		return nil, fmt.Errorf("error on update: %w", err)
+
+	if len(good.Tags) > 0 {
+		_, err = tx.Exec(ctx, "INSERT INTO tags (project_id, name) SELECT $1, unnest($2::text[]) ON CONFLICT (project_id, name) DO NOTHING", good.ProjectId, good.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("error creating tags: %w", err)
+		}
+
+		_, err = tx.Exec(ctx, "INSERT INTO goods_tags (good_id, project_id, tag_id) SELECT $1, $2, id FROM tags WHERE project_id = $2 AND name = ANY($3)", good.Id, good.ProjectId, good.Tags)
+		if err != nil {
+			return nil, fmt.Errorf("error linking tags: %w", err)
+		}
 	}
 
 	if err := tx.Commit(ctx); err != nil {
@@ -189,27 +453,102 @@ func (r *GoodsRepository) Update(ctx context.Context, good *repository.GoodModel
 	return r.getGoodById(ctx, good.Id)
 }
 
-func (r *GoodsRepository) getGoodById(ctx context.Context, id int) (*repository.GoodModel, error) {
-	goodModel := &repository.GoodModel{}
+func (r *GoodsRepository) SetCategory(ctx context.Context, good *repository.GoodModel) (*repository.GoodModel, error) {
+	r.logger.WithContext(ctx).Sampled().Info("set good category")
 
-	rows, err := r.db.Query(ctx, "SELECT * FROM goods WHERE id = $1", id)
+	exists, err := r.checkGoodExistence(ctx, good.Id, good.ProjectId)
 	if err != nil {
-		return nil, fmt.Errorf("error preparing query: %w", err)
+		return nil, fmt.Errorf("error checking good existence: %w", err)
+	}
+	if !exists {
+		return nil, ErrGoodNotExist
 	}
-	defer rows.Close()
 
-	if rows.Next() {
-		err := rows.Scan(&goodModel.Id, &goodModel.ProjectId, &goodModel.Name, &goodModel.Description, &goodModel.Priority, &goodModel.Removed, &goodModel.CreatedAt)
+	if good.CategoryId != nil {
+		var isCategoryExist bool
+		err = r.db.QueryRow(ctx, "SELECT EXISTS (SELECT id FROM categories WHERE id = $1 AND project_id = $2)", *good.CategoryId, good.ProjectId).Scan(&isCategoryExist)
 		if err != nil {
+			return nil, fmt.Errorf("error checking category existence: %w", err)
+		}
+		if !isCategoryExist {
+			return nil, ErrCategoryNotExist
+		}
+	}
+
+	_, err = r.db.Exec(ctx, "UPDATE goods SET category_id = $1 WHERE id = $2 AND project_id = $3", good.CategoryId, good.Id, good.ProjectId)
+	if err != nil {
+		return nil, fmt.Errorf("error on update category: %w", err)
+	}
+
+	return r.getGoodById(ctx, good.Id)
+}
+
+func (r *GoodsRepository) GetTags(ctx context.Context, projectId int) ([]*repository.TagModel, error) {
+	r.logger.WithContext(ctx).Sampled().Info("get tags")
+
+	tagsQuery := `SELECT t.id, t.project_id, t.name, COUNT(gt.good_id)
+		FROM tags t LEFT JOIN goods_tags gt ON gt.tag_id = t.id
+		WHERE t.project_id = $1
+		GROUP BY t.id ORDER BY t.name`
+
+	rows, err := r.db.Query(ctx, tagsQuery, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("error on get tags: %w", err)
+	}
+	defer rows.Close()
+
+	tagModels := make([]*repository.TagModel, 0)
+	for rows.Next() {
+		tagModel := new(repository.TagModel)
+		if err := rows.Scan(&tagModel.Id, &tagModel.ProjectId, &tagModel.Name, &tagModel.GoodsCount); err != nil {
 			return nil, fmt.Errorf("error scanning results: %w", err)
 		}
-	} else {
+		tagModels = append(tagModels, tagModel)
+	}
+
+	return tagModels, rows.Err()
+}
+
+func (r *GoodsRepository) getGoodById(ctx context.Context, id int) (*repository.GoodModel, error) {
+	rows, err := r.db.Query(ctx, "SELECT "+goodColumns+" FROM goods g WHERE g.id = $1", id)
+	if err != nil {
+		return nil, fmt.Errorf("error preparing query: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, fmt.Errorf("error scanning results: %w", ErrGoodNotExist)
+	}
+
+	goodModel, err := scanGood(rows)
+	if err != nil {
 		return nil, fmt.Errorf("error scanning results: %w", err)
 	}
 
 	return goodModel, nil
 }
 
+func scanGood(rows pgx.Rows) (*repository.GoodModel, error) {
+	goodModel := new(repository.GoodModel)
+	err := rows.Scan(
+		&goodModel.Id,
+		&goodModel.ProjectId,
+		&goodModel.Name,
+		&goodModel.Description,
+		&goodModel.Priority,
+		&goodModel.Removed,
+		&goodModel.CreatedAt,
+		&goodModel.CategoryId,
+		&goodModel.Attributes,
+		&goodModel.Tags,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return goodModel, nil
+}
+
 func countRemovedGoods(goods []*repository.GoodModel) int {
 	count := 0
 	for _, good := range goods {
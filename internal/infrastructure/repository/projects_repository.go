@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	postgres "rest_clickhouse/pkg/db"
+	"rest_clickhouse/pkg/logger"
+)
+
+type ProjectsRepository struct {
+	db     *postgres.DB
+	logger logger.Logger
+}
+
+func NewProjectsRepository(db *postgres.DB, logger logger.Logger) repository.ProjectsRepository {
+	return &ProjectsRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *ProjectsRepository) GetByIds(ctx context.Context, ids []int) ([]*repository.ProjectModel, error) {
+	r.logger.WithContext(ctx).Sampled().Info("get projects")
+
+	rows, err := r.db.Query(ctx, "SELECT id, name FROM projects WHERE id = ANY($1) ORDER BY id", ids)
+	if err != nil {
+		return nil, fmt.Errorf("error on get projects: %w", err)
+	}
+	defer rows.Close()
+
+	projectModels := make([]*repository.ProjectModel, 0, len(ids))
+	for rows.Next() {
+		projectModel := new(repository.ProjectModel)
+		if err := rows.Scan(&projectModel.Id, &projectModel.Name); err != nil {
+			return nil, fmt.Errorf("error scanning results: %w", err)
+		}
+		projectModels = append(projectModels, projectModel)
+	}
+
+	return projectModels, rows.Err()
+}
@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"rest_clickhouse/internal/apperrors"
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	postgres "rest_clickhouse/pkg/db"
+	"rest_clickhouse/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrWebhookNotExist  = apperrors.WebhookNotFound
+	ErrDeliveryNotExist = apperrors.WebhookDeliveryNotFound
+)
+
+const deliveryColumns = "id, webhook_id, project_id, event_type, payload, status, attempts, response_code, last_error, created_at, delivered_at"
+
+type WebhooksRepository struct {
+	db     *postgres.DB
+	logger logger.Logger
+}
+
+func NewWebhooksRepository(db *postgres.DB, logger logger.Logger) repository.WebhooksRepository {
+	return &WebhooksRepository{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (r *WebhooksRepository) Create(ctx context.Context, webhook *repository.WebhookModel) (*repository.WebhookModel, error) {
+	r.logger.WithContext(ctx).Sampled().Info("create webhook")
+
+	var isProjectExist bool
+	if err := r.db.QueryRow(ctx, "SELECT EXISTS (SELECT id FROM projects WHERE id = $1)", webhook.ProjectId).Scan(&isProjectExist); err != nil {
+		return nil, fmt.Errorf("error checking project existence: %w", err)
+	}
+	if !isProjectExist {
+		return nil, ErrProjectNotExist
+	}
+
+	createdWebhook := *webhook
+	if createdWebhook.EventTypes == nil {
+		createdWebhook.EventTypes = []string{}
+	}
+
+	q := "INSERT INTO webhooks (project_id, url, secret, event_types, active) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at"
+	err := r.db.QueryRow(ctx, q,
+		webhook.ProjectId,
+		webhook.Url,
+		webhook.Secret,
+		createdWebhook.EventTypes,
+		webhook.Active,
+	).Scan(&createdWebhook.Id, &createdWebhook.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("error on create webhook: %w", err)
+	}
+
+	return &createdWebhook, nil
+}
+
+func (r *WebhooksRepository) Get(ctx context.Context, id, projectId int) (*repository.WebhookModel, error) {
+	rows, err := r.db.Query(ctx, "SELECT id, project_id, url, secret, event_types, active, created_at FROM webhooks WHERE id = $1 AND project_id = $2", id, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("error on get webhook: %w", err)
+	}
+
+	webhooks, err := scanWebhooks(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(webhooks) == 0 {
+		return nil, ErrWebhookNotExist
+	}
+
+	return webhooks[0], nil
+}
+
+func (r *WebhooksRepository) GetList(ctx context.Context, projectId int) ([]*repository.WebhookModel, error) {
+	r.logger.WithContext(ctx).Sampled().Info("get webhooks")
+
+	rows, err := r.db.Query(ctx, "SELECT id, project_id, url, secret, event_types, active, created_at FROM webhooks WHERE project_id = $1 ORDER BY id", projectId)
+	if err != nil {
+		return nil, fmt.Errorf("error on get webhooks: %w", err)
+	}
+
+	return scanWebhooks(rows)
+}
+
+func (r *WebhooksRepository) Remove(ctx context.Context, id, projectId int) error {
+	r.logger.WithContext(ctx).Sampled().Info("remove webhook")
+
+	tag, err := r.db.Exec(ctx, "DELETE FROM webhooks WHERE id = $1 AND project_id = $2", id, projectId)
+	if err != nil {
+		return fmt.Errorf("error on remove webhook: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return ErrWebhookNotExist
+	}
+
+	return nil
+}
+
+// GetSubscribed возвращает активные подписки проекта на указанный тип события.
+func (r *WebhooksRepository) GetSubscribed(ctx context.Context, projectId int, eventType string) ([]*repository.WebhookModel, error) {
+	q := `SELECT id, project_id, url, secret, event_types, active, created_at FROM webhooks
+		WHERE project_id = $1 AND active AND (cardinality(event_types) = 0 OR $2 = ANY(event_types))`
+
+	rows, err := r.db.Query(ctx, q, projectId, eventType)
+	if err != nil {
+		return nil, fmt.Errorf("error on get subscribed webhooks: %w", err)
+	}
+
+	return scanWebhooks(rows)
+}
+
+func (r *WebhooksRepository) CreateDelivery(ctx context.Context, delivery *repository.WebhookDeliveryModel) (*repository.WebhookDeliveryModel, error) {
+	q := "INSERT INTO webhook_deliveries (webhook_id, project_id, event_type, payload, status) VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at"
+
+	createdDelivery := *delivery
+	err := r.db.QueryRow(ctx, q,
+		delivery.WebhookId,
+		delivery.ProjectId,
+		delivery.EventType,
+		string(delivery.Payload),
+		delivery.Status,
+	).Scan(&createdDelivery.Id, &createdDelivery.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("error on create delivery: %w", err)
+	}
+
+	return &createdDelivery, nil
+}
+
+func (r *WebhooksRepository) UpdateDelivery(ctx context.Context, delivery *repository.WebhookDeliveryModel) error {
+	q := "UPDATE webhook_deliveries SET status = $1, attempts = $2, response_code = $3, last_error = $4, delivered_at = $5 WHERE id = $6"
+
+	_, err := r.db.Exec(ctx, q,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.ResponseCode,
+		delivery.LastError,
+		delivery.DeliveredAt,
+		delivery.Id,
+	)
+	if err != nil {
+		return fmt.Errorf("error on update delivery: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WebhooksRepository) GetDelivery(ctx context.Context, id int64, projectId int) (*repository.WebhookDeliveryModel, error) {
+	rows, err := r.db.Query(ctx, "SELECT "+deliveryColumns+" FROM webhook_deliveries WHERE id = $1 AND project_id = $2", id, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("error on get delivery: %w", err)
+	}
+
+	deliveries, err := scanDeliveries(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(deliveries) == 0 {
+		return nil, ErrDeliveryNotExist
+	}
+
+	return deliveries[0], nil
+}
+
+func (r *WebhooksRepository) GetDeliveries(ctx context.Context, webhookId, projectId, limit, offset int) ([]*repository.WebhookDeliveryModel, error) {
+	q := "SELECT " + deliveryColumns + ` FROM webhook_deliveries
+		WHERE webhook_id = $1 AND project_id = $2
+		ORDER BY created_at DESC, id DESC OFFSET $3 LIMIT COALESCE(NULLIF($4, 0), 10)`
+
+	rows, err := r.db.Query(ctx, q, webhookId, projectId, offset, limit)
+	if err != nil {
+		return nil, fmt.Errorf("error on get deliveries: %w", err)
+	}
+
+	return scanDeliveries(rows)
+}
+
+func (r *WebhooksRepository) GetPendingDeliveries(ctx context.Context) ([]*repository.WebhookDeliveryModel, error) {
+	q := "SELECT " + deliveryColumns + ` FROM webhook_deliveries
+		WHERE status = $1 AND webhook_id IN (SELECT id FROM webhooks WHERE active)
+		ORDER BY id`
+
+	rows, err := r.db.Query(ctx, q, repository.DeliveryStatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("error on get pending deliveries: %w", err)
+	}
+
+	return scanDeliveries(rows)
+}
+
+func scanWebhooks(rows pgx.Rows) ([]*repository.WebhookModel, error) {
+	defer rows.Close()
+
+	webhookModels := make([]*repository.WebhookModel, 0)
+	for rows.Next() {
+		webhookModel := new(repository.WebhookModel)
+		err := rows.Scan(
+			&webhookModel.Id,
+			&webhookModel.ProjectId,
+			&webhookModel.Url,
+			&webhookModel.Secret,
+			&webhookModel.EventTypes,
+			&webhookModel.Active,
+			&webhookModel.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning results: %w", err)
+		}
+		webhookModels = append(webhookModels, webhookModel)
+	}
+
+	return webhookModels, rows.Err()
+}
+
+func scanDeliveries(rows pgx.Rows) ([]*repository.WebhookDeliveryModel, error) {
+	defer rows.Close()
+
+	deliveryModels := make([]*repository.WebhookDeliveryModel, 0)
+	for rows.Next() {
+		deliveryModel := new(repository.WebhookDeliveryModel)
+		err := rows.Scan(
+			&deliveryModel.Id,
+			&deliveryModel.WebhookId,
+			&deliveryModel.ProjectId,
+			&deliveryModel.EventType,
+			&deliveryModel.Payload,
+			&deliveryModel.Status,
+			&deliveryModel.Attempts,
+			&deliveryModel.ResponseCode,
+			&deliveryModel.LastError,
+			&deliveryModel.CreatedAt,
+			&deliveryModel.DeliveredAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error scanning results: %w", err)
+		}
+		deliveryModels = append(deliveryModels, deliveryModel)
+	}
+
+	return deliveryModels, rows.Err()
+}
@@ -0,0 +1,279 @@
+package interactors
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"rest_clickhouse/internal/api"
+	"rest_clickhouse/internal/apperrors"
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	"rest_clickhouse/pkg/auth"
+	"rest_clickhouse/pkg/logger"
+	"rest_clickhouse/pkg/metrics"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+var (
+	ErrInvalidApiKey        = errors.New("invalid api key")
+	ErrInvalidApiKeyRequest = apperrors.InvalidApiKey
+)
+
+const (
+	// Ключ имеет вид rk_<prefix>_<secret>: prefix хранится открыто и служит для поиска, secret - только в виде хэша.
+	apiKeyScheme       = "rk"
+	apiKeyPrefixBytes  = 6
+	apiKeySecretBytes  = 32
+	apiKeyNameMaxLen   = 128
+	apiKeyCache        = "apiKeyCache"
+	apiKeyActorPattern = "apikey:%d"
+)
+
+// ApiKeyUsageRecorder сохраняет время использования ключей в фоне, не задерживая запрос.
+type ApiKeyUsageRecorder interface {
+	Record(id int, at time.Time)
+}
+
+type ApiKeysInteractor interface {
+	CreateApiKey(ctx context.Context, apiKey *api.ApiKey) (*repository.ApiKeyModel, error)
+	GetList(ctx context.Context, projectId int) ([]*repository.ApiKeyModel, error)
+	RevokeApiKey(ctx context.Context, id, projectId int) (*repository.ApiKeyModel, error)
+	RotateApiKey(ctx context.Context, id, projectId int) (*repository.ApiKeyModel, error)
+	Authenticate(ctx context.Context, key string) (*auth.Actor, error)
+}
+
+type apiKeysInteractor struct {
+	apiKeysRepository repository.ApiKeysRepository
+	redis             *redis.Client
+	usage             ApiKeyUsageRecorder
+	cacheTTL          time.Duration
+	timeouts          Timeouts
+	logger            logger.Logger
+}
+
+// cachedApiKey - запись кэша проверки ключа. Отозванные ключи тоже кэшируются, чтобы не ходить за ними в Postgres.
+type cachedApiKey struct {
+	Id        int
+	ProjectId int
+	Hash      string
+	Role      auth.Role
+	Revoked   bool
+}
+
+func NewApiKeysInteractor(
+	apiKeysRepository repository.ApiKeysRepository,
+	redis *redis.Client,
+	usage ApiKeyUsageRecorder,
+	cacheTTL time.Duration,
+	timeouts Timeouts,
+	logger logger.Logger,
+) ApiKeysInteractor {
+	return &apiKeysInteractor{
+		apiKeysRepository: apiKeysRepository,
+		redis:             redis,
+		usage:             usage,
+		cacheTTL:          cacheTTL,
+		timeouts:          timeouts,
+		logger:            logger,
+	}
+}
+
+func (i *apiKeysInteractor) CreateApiKey(ctx context.Context, apiKey *api.ApiKey) (*repository.ApiKeyModel, error) {
+	ctx, cancel := withTimeout(ctx, i.timeouts.Write)
+	defer cancel()
+
+	name := strings.TrimSpace(apiKey.Name)
+	if name == "" || len(name) > apiKeyNameMaxLen {
+		return nil, ErrInvalidApiKeyRequest.WithField("name", fmt.Sprintf("must be 1-%d characters", apiKeyNameMaxLen))
+	}
+	if !auth.Role(apiKey.Role).Valid() {
+		return nil, ErrInvalidApiKeyRequest.WithField("role", fmt.Sprintf("unknown role %q", apiKey.Role))
+	}
+
+	key, prefix, hash, err := generateApiKey()
+	if err != nil {
+		return nil, fmt.Errorf("error generating api key: %w", err)
+	}
+
+	apiKeyModel, err := i.apiKeysRepository.Create(ctx, &repository.ApiKeyModel{
+		ProjectId: apiKey.ProjectId,
+		Name:      name,
+		Prefix:    prefix,
+		Hash:      hash,
+		Role:      apiKey.Role,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error on create api key: %w", err)
+	}
+
+	apiKeyModel.Key = key
+	return apiKeyModel, nil
+}
+
+func (i *apiKeysInteractor) GetList(ctx context.Context, projectId int) ([]*repository.ApiKeyModel, error) {
+	ctx, cancel := withTimeout(ctx, i.timeouts.Read)
+	defer cancel()
+
+	apiKeys, err := i.apiKeysRepository.GetList(ctx, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("error getting api keys from repository: %w", err)
+	}
+
+	return apiKeys, nil
+}
+
+func (i *apiKeysInteractor) RevokeApiKey(ctx context.Context, id, projectId int) (*repository.ApiKeyModel, error) {
+	ctx, cancel := withTimeout(ctx, i.timeouts.Write)
+	defer cancel()
+
+	apiKey, err := i.apiKeysRepository.Revoke(ctx, id, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("error on revoke api key: %w", err)
+	}
+
+	i.invalidate(ctx, apiKey.Prefix)
+
+	return apiKey, nil
+}
+
+// RotateApiKey выдает новый ключ вместо старого, старый перестает действовать сразу.
+func (i *apiKeysInteractor) RotateApiKey(ctx context.Context, id, projectId int) (*repository.ApiKeyModel, error) {
+	ctx, cancel := withTimeout(ctx, i.timeouts.Write)
+	defer cancel()
+
+	current, err := i.apiKeysRepository.Get(ctx, id, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("error on get api key: %w", err)
+	}
+
+	key, prefix, hash, err := generateApiKey()
+	if err != nil {
+		return nil, fmt.Errorf("error generating api key: %w", err)
+	}
+
+	apiKey, err := i.apiKeysRepository.Rotate(ctx, id, projectId, prefix, hash)
+	if err != nil {
+		return nil, fmt.Errorf("error on rotate api key: %w", err)
+	}
+
+	i.invalidate(ctx, current.Prefix)
+
+	apiKey.Key = key
+	return apiKey, nil
+}
+
+// Authenticate проверяет ключ и возвращает вызывающего с ролью ключа в его проекте.
+// Неизвестный, отозванный или неверный ключ - ErrInvalidApiKey, остальные ошибки означают недоступность хранилищ.
+func (i *apiKeysInteractor) Authenticate(ctx context.Context, key string) (*auth.Actor, error) {
+	prefix, ok := apiKeyPrefix(key)
+	if !ok {
+		return nil, ErrInvalidApiKey
+	}
+
+	ctx, cancel := withTimeout(ctx, i.timeouts.Read)
+	defer cancel()
+
+	apiKey, err := i.lookup(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	if apiKey.Revoked || subtle.ConstantTimeCompare([]byte(apiKey.Hash), []byte(hashApiKey(key))) != 1 {
+		return nil, ErrInvalidApiKey
+	}
+
+	i.usage.Record(apiKey.Id, time.Now().UTC())
+
+	return &auth.Actor{
+		Id:       fmt.Sprintf(apiKeyActorPattern, apiKey.Id),
+		Method:   auth.MethodApiKey,
+		Projects: map[int]auth.Role{apiKey.ProjectId: apiKey.Role},
+	}, nil
+}
+
+// lookup ищет ключ в Redis, затем в Postgres. Недоступный Redis не мешает аутентификации.
+func (i *apiKeysInteractor) lookup(ctx context.Context, prefix string) (*cachedApiKey, error) {
+	cacheKey := apiKeyCache + "-" + prefix
+	cacheBytes, err := cacheGet(ctx, i.redis, cacheKey)
+	if err == nil {
+		var apiKey cachedApiKey
+		if err := json.Unmarshal(cacheBytes, &apiKey); err == nil {
+			metrics.CacheRequestsTotal.WithLabelValues(apiKeyCache, metrics.CacheHit).Inc()
+			return &apiKey, nil
+		}
+	}
+	if err != nil && !errors.Is(err, redis.Nil) {
+		metrics.CacheRequestsTotal.WithLabelValues(apiKeyCache, metrics.CacheError).Inc()
+		i.logger.WithContext(ctx).WarnF("error getting api key from cache: %v", err)
+	} else {
+		metrics.CacheRequestsTotal.WithLabelValues(apiKeyCache, metrics.CacheMiss).Inc()
+	}
+
+	apiKeyModel, err := i.apiKeysRepository.GetByPrefix(ctx, prefix)
+	if errors.Is(err, repository.ErrApiKeyNotExist) {
+		return nil, ErrInvalidApiKey
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error on get api key: %w", err)
+	}
+
+	apiKey := &cachedApiKey{
+		Id:        apiKeyModel.Id,
+		ProjectId: apiKeyModel.ProjectId,
+		Hash:      apiKeyModel.Hash,
+		Role:      auth.Role(apiKeyModel.Role),
+		Revoked:   apiKeyModel.RevokedAt != nil,
+	}
+	if data, err := json.Marshal(apiKey); err == nil {
+		if err := cacheSet(ctx, i.redis, cacheKey, data, i.cacheTTL); err != nil {
+			i.logger.WithContext(ctx).WarnF("error setting api key in cache: %v", err)
+		}
+	}
+
+	return apiKey, nil
+}
+
+// invalidate удаляет ключ из кэша. Ошибка только логируется: запись в любом случае истечет через cacheTTL.
+func (i *apiKeysInteractor) invalidate(ctx context.Context, prefix string) {
+	if err := cacheDel(ctx, i.redis, apiKeyCache+"-"+prefix); err != nil {
+		i.logger.WithContext(ctx).ErrorF("error invalidating api key cache: %v", err)
+	}
+}
+
+func generateApiKey() (key, prefix, hash string, err error) {
+	prefixBytes := make([]byte, apiKeyPrefixBytes)
+	if _, err := rand.Read(prefixBytes); err != nil {
+		return "", "", "", err
+	}
+	secret := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", "", err
+	}
+
+	prefix = hex.EncodeToString(prefixBytes)
+	key = apiKeyScheme + "_" + prefix + "_" + hex.EncodeToString(secret)
+
+	return key, prefix, hashApiKey(key), nil
+}
+
+func apiKeyPrefix(key string) (string, bool) {
+	parts := strings.Split(key, "_")
+	if len(parts) != 3 || parts[0] != apiKeyScheme || len(parts[1]) != apiKeyPrefixBytes*2 || parts[2] == "" {
+		return "", false
+	}
+
+	return parts[1], true
+}
+
+// hashApiKey - у ключа 256 бит случайности, поэтому медленный хэш вроде bcrypt не нужен.
+func hashApiKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,163 @@
+package interactors
+
+import (
+	"fmt"
+	"rest_clickhouse/internal/apperrors"
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidAttributes = apperrors.InvalidAttributes
+
+// attributesError собирает нарушения схемы атрибутов по каждому полю в порядке имен.
+func attributesError(violations map[string]string) error {
+	names := make([]string, 0, len(violations))
+	for name := range violations {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	err := ErrInvalidAttributes
+	for _, name := range names {
+		err = err.WithField(name, violations[name])
+	}
+
+	return err
+}
+
+// validateAttributes проверяет атрибуты товара по схеме проекта: неизвестные поля, типы,
+// допустимые значения enum и наличие обязательных полей.
+func validateAttributes(schema []*repository.AttributeModel, attributes map[string]interface{}) error {
+	violations := make(map[string]string)
+	byName := make(map[string]*repository.AttributeModel, len(schema))
+	for _, attribute := range schema {
+		byName[attribute.Name] = attribute
+	}
+
+	for name, value := range attributes {
+		attribute, ok := byName[name]
+		if !ok {
+			violations[name] = "unknown attribute"
+			continue
+		}
+
+		if msg := checkAttributeValue(attribute, value); msg != "" {
+			violations[name] = msg
+		}
+	}
+
+	for _, attribute := range schema {
+		if _, ok := attributes[attribute.Name]; attribute.Required && !ok {
+			violations[attribute.Name] = "required"
+		}
+	}
+
+	if len(violations) > 0 {
+		return attributesError(violations)
+	}
+
+	return nil
+}
+
+func checkAttributeValue(attribute *repository.AttributeModel, value interface{}) string {
+	switch attribute.Type {
+	case repository.AttributeTypeString:
+		if _, ok := value.(string); !ok {
+			return "must be a string"
+		}
+	case repository.AttributeTypeNumber:
+		if _, ok := value.(float64); !ok {
+			return "must be a number"
+		}
+	case repository.AttributeTypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return "must be a boolean"
+		}
+	case repository.AttributeTypeEnum:
+		str, ok := value.(string)
+		if !ok || !contains(attribute.EnumValues, str) {
+			return fmt.Sprintf("must be one of [%s]", strings.Join(attribute.EnumValues, ", "))
+		}
+	}
+
+	return ""
+}
+
+// parseAttributesFilter приводит строковые значения фильтра из query к типам схемы,
+// чтобы фильтр можно было выполнить через jsonb containment.
+func parseAttributesFilter(schema []*repository.AttributeModel, filter map[string]string) (map[string]interface{}, error) {
+	violations := make(map[string]string)
+	byName := make(map[string]*repository.AttributeModel, len(schema))
+	for _, attribute := range schema {
+		byName[attribute.Name] = attribute
+	}
+
+	parsed := make(map[string]interface{}, len(filter))
+	for name, raw := range filter {
+		attribute, ok := byName[name]
+		if !ok {
+			violations[name] = "unknown attribute"
+			continue
+		}
+
+		switch attribute.Type {
+		case repository.AttributeTypeNumber:
+			value, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				violations[name] = "must be a number"
+				continue
+			}
+			parsed[name] = value
+		case repository.AttributeTypeBoolean:
+			value, err := strconv.ParseBool(raw)
+			if err != nil {
+				violations[name] = "must be a boolean"
+				continue
+			}
+			parsed[name] = value
+		default:
+			parsed[name] = raw
+		}
+	}
+
+	if len(violations) > 0 {
+		return nil, attributesError(violations)
+	}
+
+	return parsed, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mergePatch применяет JSON Merge Patch (RFC 7396) к атрибутам: null удаляет ключ, вложенные объекты
+// сливаются рекурсивно, остальные значения заменяются. target не меняется.
+func mergePatch(target, patch map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(target)+len(patch))
+	for key, value := range target {
+		merged[key] = value
+	}
+
+	for key, value := range patch {
+		switch value := value.(type) {
+		case nil:
+			delete(merged, key)
+		case map[string]interface{}:
+			nested, _ := merged[key].(map[string]interface{})
+			merged[key] = mergePatch(nested, value)
+		default:
+			merged[key] = value
+		}
+	}
+
+	return merged
+}
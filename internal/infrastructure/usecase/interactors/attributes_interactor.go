@@ -0,0 +1,93 @@
+package interactors
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"rest_clickhouse/internal/api"
+	"rest_clickhouse/internal/apperrors"
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	"rest_clickhouse/pkg/logger"
+)
+
+var ErrInvalidAttributeSchema = apperrors.InvalidAttributeSchema
+
+var attributeNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+
+type AttributesInteractor interface {
+	CreateAttribute(ctx context.Context, attribute *api.Attribute) (*repository.AttributeModel, error)
+	GetList(ctx context.Context, projectId int) ([]*repository.AttributeModel, error)
+	RemoveAttribute(ctx context.Context, name string, projectId int) error
+}
+
+type attributesInteractor struct {
+	attributesRepository repository.AttributesRepository
+	timeouts             Timeouts
+	logger               logger.Logger
+}
+
+func NewAttributesInteractor(attributesRepository repository.AttributesRepository, timeouts Timeouts, logger logger.Logger) AttributesInteractor {
+	return &attributesInteractor{
+		attributesRepository: attributesRepository,
+		timeouts:             timeouts,
+		logger:               logger,
+	}
+}
+
+func (i *attributesInteractor) CreateAttribute(ctx context.Context, attribute *api.Attribute) (*repository.AttributeModel, error) {
+	ctx, cancel := withTimeout(ctx, i.timeouts.Write)
+	defer cancel()
+
+	if !attributeNameRegexp.MatchString(attribute.Name) {
+		return nil, ErrInvalidAttributeSchema.WithField("name", fmt.Sprintf("must match %s", attributeNameRegexp))
+	}
+
+	switch attribute.Type {
+	case repository.AttributeTypeString, repository.AttributeTypeNumber, repository.AttributeTypeBoolean:
+		if len(attribute.EnumValues) > 0 {
+			return nil, ErrInvalidAttributeSchema.WithField("enumValues", "allowed only for enum type")
+		}
+	case repository.AttributeTypeEnum:
+		if len(attribute.EnumValues) == 0 {
+			return nil, ErrInvalidAttributeSchema.WithField("enumValues", "required for enum type")
+		}
+	default:
+		return nil, ErrInvalidAttributeSchema.WithField("type", fmt.Sprintf("unknown type %q", attribute.Type))
+	}
+
+	attributeModel, err := i.attributesRepository.Create(ctx, &repository.AttributeModel{
+		ProjectId:  attribute.ProjectId,
+		Name:       attribute.Name,
+		Type:       attribute.Type,
+		Required:   attribute.Required,
+		EnumValues: attribute.EnumValues,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error on create attribute: %w", err)
+	}
+
+	return attributeModel, nil
+}
+
+func (i *attributesInteractor) GetList(ctx context.Context, projectId int) ([]*repository.AttributeModel, error) {
+	ctx, cancel := withTimeout(ctx, i.timeouts.Read)
+	defer cancel()
+
+	attributes, err := i.attributesRepository.GetList(ctx, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("error getting attributes from repository: %w", err)
+	}
+
+	return attributes, nil
+}
+
+func (i *attributesInteractor) RemoveAttribute(ctx context.Context, name string, projectId int) error {
+	ctx, cancel := withTimeout(ctx, i.timeouts.Write)
+	defer cancel()
+
+	if err := i.attributesRepository.Remove(ctx, name, projectId); err != nil {
+		return fmt.Errorf("error on remove attribute: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package interactors
+
+import (
+	"context"
+	"fmt"
+	"rest_clickhouse/internal/apperrors"
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	"rest_clickhouse/pkg/audit"
+	"rest_clickhouse/pkg/auth"
+	"rest_clickhouse/pkg/logger"
+)
+
+var ErrInvalidAuditFilter = apperrors.InvalidAuditFilter
+
+const (
+	auditDefaultLimit = 100
+	auditMaxLimit     = 1000
+)
+
+type AuditInteractor interface {
+	GetList(ctx context.Context, filter repository.AuditFilter) ([]*repository.AuditRecordModel, error)
+}
+
+type auditInteractor struct {
+	auditRepository repository.AuditRepository
+	timeouts        Timeouts
+	logger          logger.Logger
+}
+
+func NewAuditInteractor(auditRepository repository.AuditRepository, timeouts Timeouts, logger logger.Logger) AuditInteractor {
+	return &auditInteractor{
+		auditRepository: auditRepository,
+		timeouts:        timeouts,
+		logger:          logger,
+	}
+}
+
+func (i *auditInteractor) GetList(ctx context.Context, filter repository.AuditFilter) ([]*repository.AuditRecordModel, error) {
+	ctx, cancel := withTimeout(ctx, i.timeouts.Search)
+	defer cancel()
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return nil, ErrInvalidAuditFilter.WithField("to", "must not be before from")
+	}
+	if filter.Limit < 0 || filter.Limit > auditMaxLimit {
+		return nil, ErrInvalidAuditFilter.WithField("limit", fmt.Sprintf("must be 0-%d", auditMaxLimit))
+	}
+	if filter.Offset < 0 {
+		return nil, ErrInvalidAuditFilter.WithField("offset", "must not be negative")
+	}
+	if filter.Limit == 0 {
+		filter.Limit = auditDefaultLimit
+	}
+
+	records, err := i.auditRepository.GetList(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("error getting audit records from repository: %w", err)
+	}
+
+	return records, nil
+}
+
+// auditFromContext собирает автора изменения из контекста запроса для события.
+func auditFromContext(ctx context.Context) repository.AuditModel {
+	var model repository.AuditModel
+	if actor, ok := auth.ActorFromContext(ctx); ok {
+		model.Actor = actor.Id
+		model.AuthMethod = actor.Method
+	}
+	if client, ok := audit.ClientFromContext(ctx); ok {
+		model.ClientIP = client.IP
+		model.UserAgent = client.UserAgent
+	}
+	if requestId, ok := logger.RequestIdFromContext(ctx); ok {
+		model.RequestId = requestId
+	}
+
+	return model
+}
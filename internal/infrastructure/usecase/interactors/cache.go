@@ -0,0 +1,80 @@
+package interactors
+
+import (
+	"context"
+	"errors"
+	"rest_clickhouse/pkg/tracing"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CacheSettings хранит TTL кэша, который можно поменять без перезапуска.
+type CacheSettings struct {
+	goodsListTTL atomic.Int64
+}
+
+func NewCacheSettings(goodsListTTL time.Duration) *CacheSettings {
+	settings := &CacheSettings{}
+	settings.SetGoodsListTTL(goodsListTTL)
+
+	return settings
+}
+
+func (s *CacheSettings) GoodsListTTL() time.Duration {
+	return time.Duration(s.goodsListTTL.Load())
+}
+
+func (s *CacheSettings) SetGoodsListTTL(ttl time.Duration) {
+	s.goodsListTTL.Store(int64(ttl))
+}
+
+func cacheGet(ctx context.Context, client *redis.Client, key string) ([]byte, error) {
+	ctx, span := tracing.StartSpan(ctx, "redis.GET", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+
+	// Клиент redis v6 не прерывает команды по контексту, поэтому отмененный запрос не идет в redis.
+	if err := ctx.Err(); err != nil {
+		tracing.RecordError(span, err)
+		return nil, err
+	}
+
+	data, err := client.WithContext(ctx).Get(key).Bytes()
+	if !errors.Is(err, redis.Nil) {
+		tracing.RecordError(span, err)
+	}
+
+	return data, err
+}
+
+func cacheSet(ctx context.Context, client *redis.Client, key string, value []byte, ttl time.Duration) error {
+	ctx, span := tracing.StartSpan(ctx, "redis.SET", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+
+	if err := ctx.Err(); err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	err := client.WithContext(ctx).Set(key, value, ttl).Err()
+	tracing.RecordError(span, err)
+
+	return err
+}
+
+func cacheDel(ctx context.Context, client *redis.Client, keys ...string) error {
+	ctx, span := tracing.StartSpan(ctx, "redis.DEL", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+
+	if err := ctx.Err(); err != nil {
+		tracing.RecordError(span, err)
+		return err
+	}
+
+	err := client.WithContext(ctx).Del(keys...).Err()
+	tracing.RecordError(span, err)
+
+	return err
+}
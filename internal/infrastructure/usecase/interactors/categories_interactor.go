@@ -0,0 +1,66 @@
+package interactors
+
+import (
+	"context"
+	"fmt"
+	"rest_clickhouse/internal/api"
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	"rest_clickhouse/pkg/logger"
+	"strings"
+)
+
+type CategoriesInteractor interface {
+	CreateCategory(ctx context.Context, category *api.Category) (*repository.CategoryModel, error)
+	GetList(ctx context.Context, projectId int) ([]*repository.CategoryModel, error)
+	RemoveCategory(ctx context.Context, id, projectId int) error
+}
+
+type categoriesInteractor struct {
+	categoriesRepository repository.CategoriesRepository
+	timeouts             Timeouts
+	logger               logger.Logger
+}
+
+func NewCategoriesInteractor(categoriesRepository repository.CategoriesRepository, timeouts Timeouts, logger logger.Logger) CategoriesInteractor {
+	return &categoriesInteractor{
+		categoriesRepository: categoriesRepository,
+		timeouts:             timeouts,
+		logger:               logger,
+	}
+}
+
+func (i *categoriesInteractor) CreateCategory(ctx context.Context, category *api.Category) (*repository.CategoryModel, error) {
+	ctx, cancel := withTimeout(ctx, i.timeouts.Write)
+	defer cancel()
+
+	categoryDTO := repository.NewCategoryCreateModel(category.ProjectId, category.ParentId, strings.TrimSpace(category.Name))
+	categoryModel, err := i.categoriesRepository.Create(ctx, categoryDTO)
+	if err != nil {
+		return nil, fmt.Errorf("error on create category: %w", err)
+	}
+
+	return categoryModel, nil
+}
+
+func (i *categoriesInteractor) GetList(ctx context.Context, projectId int) ([]*repository.CategoryModel, error) {
+	ctx, cancel := withTimeout(ctx, i.timeouts.Read)
+	defer cancel()
+
+	categories, err := i.categoriesRepository.GetList(ctx, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("error getting categories from repository: %w", err)
+	}
+
+	return categories, nil
+}
+
+func (i *categoriesInteractor) RemoveCategory(ctx context.Context, id, projectId int) error {
+	ctx, cancel := withTimeout(ctx, i.timeouts.Write)
+	defer cancel()
+
+	if err := i.categoriesRepository.Remove(ctx, id, projectId); err != nil {
+		return fmt.Errorf("error on remove category: %w", err)
+	}
+
+	return nil
+}
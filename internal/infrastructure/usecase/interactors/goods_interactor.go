@@ -6,75 +6,171 @@ import (
 	"errors"
 	"fmt"
 	"rest_clickhouse/internal/api"
+	"rest_clickhouse/internal/apperrors"
 	"rest_clickhouse/internal/infrastructure/queue"
 	nats_client "rest_clickhouse/internal/infrastructure/queue/nats"
 	"rest_clickhouse/internal/infrastructure/usecase/repository"
 	postgres "rest_clickhouse/pkg/db"
+	"rest_clickhouse/pkg/features"
 	"rest_clickhouse/pkg/logger"
-	"time"
+	"rest_clickhouse/pkg/metrics"
+	"rest_clickhouse/pkg/tracing"
+	"sort"
+	"strings"
 
 	"github.com/go-redis/redis"
 )
 
 type GoodsInteractor interface {
-	CreateGood(good *api.Good) (*repository.GoodModel, error)
-	RemoveGood(good *api.Good) (*repository.GoodModel, error)
-	UpdateGood(good *api.Good) (*repository.GoodModel, error)
-	GetList(limit, offset int) (*repository.GoodModelList, error)
+	CreateGood(ctx context.Context, good *api.Good) (*repository.GoodModel, error)
+	GetGood(ctx context.Context, id, projectId int) (*repository.GoodModel, error)
+	RemoveGood(ctx context.Context, good *api.Good) (*repository.GoodModel, error)
+	PatchGood(ctx context.Context, patch *api.GoodPatch) (*repository.GoodModel, error)
+	ReplaceGood(ctx context.Context, patch *api.GoodPatch) (*repository.GoodModel, error)
+	GetList(ctx context.Context, limit, offset int, filter api.GoodListFilter) (*repository.GoodModelList, error)
+	SearchGoods(ctx context.Context, query string, projectId, limit, offset int) (*repository.GoodSearchModelList, error)
+	SetGoodTags(ctx context.Context, good *api.Good) (*repository.GoodModel, error)
+	SetGoodCategory(ctx context.Context, good *api.Good) (*repository.GoodModel, error)
+	GetTags(ctx context.Context, projectId int) ([]*repository.TagModel, error)
 }
 
 type goodsInteractor struct {
-	db              *postgres.DB
-	goodsRepository repository.GoodsRepository
-	pubSub          queue.PubSub
-	redis           *redis.Client
-	logger          logger.Logger
+	db                   *postgres.DB
+	goodsRepository      repository.GoodsRepository
+	attributesRepository repository.AttributesRepository
+	pubSub               queue.PubSub
+	redis                *redis.Client
+	cache                *CacheSettings
+	features             *features.Flags
+	timeouts             Timeouts
+	logger               logger.Logger
 }
 
 const goodCache = "goodCache"
 
-func NewGoodsInteractor(goodsRepository repository.GoodsRepository, redis *redis.Client, pubSub queue.PubSub, logger logger.Logger) GoodsInteractor {
+func NewGoodsInteractor(
+	goodsRepository repository.GoodsRepository,
+	attributesRepository repository.AttributesRepository,
+	redis *redis.Client,
+	pubSub queue.PubSub,
+	cache *CacheSettings,
+	features *features.Flags,
+	timeouts Timeouts,
+	logger logger.Logger,
+) GoodsInteractor {
 	return &goodsInteractor{
-		goodsRepository: goodsRepository,
-		redis:           redis,
-		pubSub:          pubSub,
-		logger:          logger,
+		goodsRepository:      goodsRepository,
+		attributesRepository: attributesRepository,
+		redis:                redis,
+		pubSub:               pubSub,
+		cache:                cache,
+		features:             features,
+		timeouts:             timeouts,
+		logger:               logger,
 	}
 }
 
-func (i *goodsInteractor) CreateGood(good *api.Good) (*repository.GoodModel, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (i *goodsInteractor) CreateGood(ctx context.Context, good *api.Good) (*repository.GoodModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "GoodsInteractor.CreateGood")
+	defer span.End()
+
+	ctx, cancel := withTimeout(ctx, i.timeouts.Write)
 	defer cancel()
 
-	goodDTO := repository.NewGoodCreateModel(good.ProjectId, good.Name)
+	schema, err := i.attributesRepository.GetList(ctx, good.ProjectId)
+	if err != nil {
+		return nil, fmt.Errorf("error getting attributes schema: %w", err)
+	}
+
+	if err := validateAttributes(schema, good.Attributes); err != nil {
+		return nil, err
+	}
+
+	priority := repository.GoodDefaultPriority
+	if good.Priority != nil {
+		priority = *good.Priority
+	}
+
+	goodDTO := repository.NewGoodCreateModel(good.ProjectId, good.Name, good.Description, priority, good.Attributes)
 	goodModel, err := i.goodsRepository.Create(ctx, goodDTO)
 	if err != nil {
 		return nil, fmt.Errorf("error on create good: %w", err)
 	}
 
-	data, err := json.Marshal(goodModel)
-	if err != nil {
-		return goodModel, fmt.Errorf("error marshaling goodModel: %w", err)
+	if err := i.publish(ctx, repository.GoodCreatedEvent, goodModel); err != nil {
+		return goodModel, err
 	}
 
-	if err := i.pubSub.Pub(nats_client.EventTopicName, data); err != nil {
-		return goodModel, fmt.Errorf("error publishing event: %w", err)
+	return goodModel, nil
+}
+
+func (i *goodsInteractor) GetGood(ctx context.Context, id, projectId int) (*repository.GoodModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "GoodsInteractor.GetGood")
+	defer span.End()
+
+	ctx, cancel := withTimeout(ctx, i.timeouts.Read)
+	defer cancel()
+
+	goodModel, err := i.goodsRepository.Get(ctx, id, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("error on get good: %w", err)
 	}
 
 	return goodModel, nil
 }
 
-func (i *goodsInteractor) GetList(limit, offset int) (*repository.GoodModelList, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (i *goodsInteractor) GetList(ctx context.Context, limit, offset int, filter api.GoodListFilter) (*repository.GoodModelList, error) {
+	ctx, span := tracing.StartSpan(ctx, "GoodsInteractor.GetList")
+	defer span.End()
+
+	ctx, cancel := withTimeout(ctx, i.timeouts.Read)
 	defer cancel()
 
-	cacheBytes, err := i.redis.Get(goodCache).Bytes()
+	listFilter := repository.GoodListFilter{
+		ProjectId:  filter.ProjectId,
+		Tag:        filter.Tag,
+		CategoryId: filter.CategoryId,
+	}
+
+	if len(filter.Attributes) > 0 {
+		if filter.ProjectId == 0 {
+			return nil, ErrInvalidAttributes.WithField("projectId", "required to filter by attributes")
+		}
+
+		schema, err := i.attributesRepository.GetList(ctx, filter.ProjectId)
+		if err != nil {
+			return nil, fmt.Errorf("error getting attributes schema: %w", err)
+		}
+
+		listFilter.Attributes, err = parseAttributesFilter(schema, filter.Attributes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if !i.features.GoodsListCache() {
+		goods, err := i.goodsRepository.GetList(ctx, limit, offset, listFilter)
+		if err != nil {
+			return nil, fmt.Errorf("error getting list from repository: %w", err)
+		}
+		return goods, nil
+	}
+
+	filterBytes, err := json.Marshal(filter)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling filter: %w", err)
+	}
+
+	cacheKey := fmt.Sprintf("%s-%d-%d-%s", goodCache, limit, offset, filterBytes)
+	cacheBytes, err := cacheGet(ctx, i.redis, cacheKey)
 	if err != nil && !errors.Is(err, redis.Nil) {
+		metrics.CacheRequestsTotal.WithLabelValues(goodCache, metrics.CacheError).Inc()
 		return nil, fmt.Errorf("error getting data from cache: %w", err)
 	}
 
 	if errors.Is(err, redis.Nil) {
-		goods, err := i.goodsRepository.GetList(ctx, limit, offset)
+		metrics.CacheRequestsTotal.WithLabelValues(goodCache, metrics.CacheMiss).Inc()
+		goods, err := i.goodsRepository.GetList(ctx, limit, offset, listFilter)
 		if err != nil {
 			return nil, fmt.Errorf("error getting list from repository: %w", err)
 		}
@@ -82,12 +178,14 @@ func (i *goodsInteractor) GetList(limit, offset int) (*repository.GoodModelList,
 		if err != nil {
 			return nil, fmt.Errorf("error marshaling goods: %w", err)
 		}
-		if err := i.redis.Set(goodCache, goodsBytes, time.Minute).Err(); err != nil {
+		if err := cacheSet(ctx, i.redis, cacheKey, goodsBytes, i.cache.GoodsListTTL()); err != nil {
 			return nil, fmt.Errorf("error setting data in cache: %w", err)
 		}
 		return goods, nil
 	}
 
+	metrics.CacheRequestsTotal.WithLabelValues(goodCache, metrics.CacheHit).Inc()
+
 	var goods repository.GoodModelList
 	if err := json.Unmarshal(cacheBytes, &goods); err != nil {
 		return nil, fmt.Errorf("error unmarshaling cached data: %w", err)
@@ -96,8 +194,31 @@ func (i *goodsInteractor) GetList(limit, offset int) (*repository.GoodModelList,
 	return &goods, nil
 }
 
-func (i *goodsInteractor) RemoveGood(good *api.Good) (*repository.GoodModel, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (i *goodsInteractor) SearchGoods(ctx context.Context, query string, projectId, limit, offset int) (*repository.GoodSearchModelList, error) {
+	ctx, span := tracing.StartSpan(ctx, "GoodsInteractor.SearchGoods")
+	defer span.End()
+
+	ctx, cancel := withTimeout(ctx, i.timeouts.Search)
+	defer cancel()
+
+	goods, err := i.goodsRepository.Search(ctx, repository.GoodSearchQuery{
+		Query:     query,
+		ProjectId: projectId,
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error on search goods: %w", err)
+	}
+
+	return goods, nil
+}
+
+func (i *goodsInteractor) RemoveGood(ctx context.Context, good *api.Good) (*repository.GoodModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "GoodsInteractor.RemoveGood")
+	defer span.End()
+
+	ctx, cancel := withTimeout(ctx, i.timeouts.Write)
 	defer cancel()
 	goodDTO := repository.NewGoodRemoveModel(good.Id, good.ProjectId)
 
@@ -106,36 +227,187 @@ func (i *goodsInteractor) RemoveGood(good *api.Good) (*repository.GoodModel, err
 		return nil, fmt.Errorf("error on remove good: %w", err)
 	}
 
-	data, err := json.Marshal(goodModel)
+	if err := i.publish(ctx, repository.GoodRemovedEvent, goodModel); err != nil {
+		return nil, err
+	}
+
+	return goodModel, nil
+}
+
+// PatchGood меняет только переданные в патче поля, null сбрасывает поле к значению по умолчанию.
+func (i *goodsInteractor) PatchGood(ctx context.Context, patch *api.GoodPatch) (*repository.GoodModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "GoodsInteractor.PatchGood")
+	defer span.End()
+
+	return i.updateGood(ctx, patch, false)
+}
+
+// ReplaceGood заменяет название, описание, приоритет и атрибуты товара, отсутствующие поля сбрасываются.
+// Теги и категория меняются отдельно.
+func (i *goodsInteractor) ReplaceGood(ctx context.Context, patch *api.GoodPatch) (*repository.GoodModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "GoodsInteractor.ReplaceGood")
+	defer span.End()
+
+	return i.updateGood(ctx, patch, true)
+}
+
+func (i *goodsInteractor) updateGood(ctx context.Context, patch *api.GoodPatch, replace bool) (*repository.GoodModel, error) {
+	ctx, cancel := withTimeout(ctx, i.timeouts.Write)
+	defer cancel()
+
+	goodDTO := repository.NewGoodUpdateModel(patch.Id, patch.ProjectId)
+	switch {
+	case patch.Name != nil:
+		goodDTO.Name = patch.Name
+	case replace:
+		return nil, apperrors.InvalidRequest.WithField("name", "required")
+	case patch.Has("name"):
+		return nil, apperrors.InvalidRequest.WithField("name", "must not be null")
+	}
+
+	if patch.Description != nil || replace || patch.Has("description") {
+		description := ""
+		if patch.Description != nil {
+			description = *patch.Description
+		}
+		goodDTO.Description = &description
+	}
+
+	if patch.Priority != nil || replace || patch.Has("priority") {
+		priority := repository.GoodDefaultPriority
+		if patch.Priority != nil {
+			priority = *patch.Priority
+		}
+		goodDTO.Priority = &priority
+	}
+
+	if patch.Attributes != nil || replace || patch.Has("attributes") {
+		schema, err := i.attributesRepository.GetList(ctx, patch.ProjectId)
+		if err != nil {
+			return nil, fmt.Errorf("error getting attributes schema: %w", err)
+		}
+
+		merge := func(current map[string]interface{}) (map[string]interface{}, error) {
+			attributes := mergePatch(current, patch.Attributes)
+			if err := validateAttributes(schema, attributes); err != nil {
+				return nil, err
+			}
+			return attributes, nil
+		}
+
+		// Патч сливается с текущими атрибутами в транзакции репозитория, чтобы параллельные
+		// PATCH с разными ключами не затирали друг друга. Замена и null не зависят от текущих атрибутов.
+		if !replace && patch.Attributes != nil {
+			goodDTO.MergeAttributes = merge
+		} else {
+			attributes, err := merge(map[string]interface{}{})
+			if err != nil {
+				return nil, err
+			}
+			goodDTO.Attributes = attributes
+		}
+	}
+
+	goodModel, err := i.goodsRepository.Update(ctx, goodDTO)
 	if err != nil {
-		return nil, fmt.Errorf("error marshaling goodModel: %w", err)
+		return nil, fmt.Errorf("error on update good: %w", err)
 	}
 
-	if err := i.pubSub.Pub(nats_client.EventTopicName, data); err != nil {
-		return nil, fmt.Errorf("error publishing event: %w", err)
+	if err := i.publish(ctx, repository.GoodUpdatedEvent, goodModel); err != nil {
+		return nil, err
 	}
 
 	return goodModel, nil
 }
 
-func (i *goodsInteractor) UpdateGood(good *api.Good) (*repository.GoodModel, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (i *goodsInteractor) SetGoodTags(ctx context.Context, good *api.Good) (*repository.GoodModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "GoodsInteractor.SetGoodTags")
+	defer span.End()
+
+	ctx, cancel := withTimeout(ctx, i.timeouts.Write)
 	defer cancel()
-	goodDTO := repository.NewGoodUpdateModel(good.Id, good.ProjectId, good.Name, good.Description)
+	goodDTO := repository.NewGoodTagsModel(good.Id, good.ProjectId, normalizeTags(good.Tags))
 
-	goodModel, err := i.goodsRepository.Update(ctx, goodDTO)
+	goodModel, err := i.goodsRepository.SetTags(ctx, goodDTO)
 	if err != nil {
-		return nil, fmt.Errorf("error on update good: %w", err)
+		return nil, fmt.Errorf("error on set good tags: %w", err)
 	}
 
-	data, err := json.Marshal(goodModel)
+	if err := i.publish(ctx, repository.GoodUpdatedEvent, goodModel); err != nil {
+		return nil, err
+	}
+
+	return goodModel, nil
+}
+
+func (i *goodsInteractor) SetGoodCategory(ctx context.Context, good *api.Good) (*repository.GoodModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "GoodsInteractor.SetGoodCategory")
+	defer span.End()
+
+	ctx, cancel := withTimeout(ctx, i.timeouts.Write)
+	defer cancel()
+	goodDTO := repository.NewGoodCategoryModel(good.Id, good.ProjectId, good.CategoryId)
+
+	goodModel, err := i.goodsRepository.SetCategory(ctx, goodDTO)
 	if err != nil {
-		return nil, fmt.Errorf("error marshaling goodModel: %w", err)
+		return nil, fmt.Errorf("error on set good category: %w", err)
 	}
 
-	if err := i.pubSub.Pub(nats_client.EventTopicName, data); err != nil {
-		return nil, fmt.Errorf("error publishing event: %w", err)
+	if err := i.publish(ctx, repository.GoodUpdatedEvent, goodModel); err != nil {
+		return nil, err
 	}
 
 	return goodModel, nil
 }
+
+func (i *goodsInteractor) GetTags(ctx context.Context, projectId int) ([]*repository.TagModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "GoodsInteractor.GetTags")
+	defer span.End()
+
+	ctx, cancel := withTimeout(ctx, i.timeouts.Read)
+	defer cancel()
+
+	tags, err := i.goodsRepository.GetTags(ctx, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("error on get tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// publish отправляет изменение товара в топик событий.
+func (i *goodsInteractor) publish(ctx context.Context, eventType string, goodModel *repository.GoodModel) error {
+	event := repository.GoodEvent{GoodModel: *goodModel, AuditModel: auditFromContext(ctx), Type: eventType}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error marshaling goodModel: %w", err)
+	}
+
+	// Изменение уже закоммичено, поэтому отмена запроса не должна терять событие.
+	if err := i.pubSub.Pub(context.WithoutCancel(ctx), nats_client.EventTopicName, data); err != nil {
+		return fmt.Errorf("error publishing event: %w", err)
+	}
+
+	return nil
+}
+
+// normalizeTags приводит теги к нижнему регистру, убирает пустые значения и дубликаты.
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]struct{}, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		normalized = append(normalized, tag)
+	}
+	sort.Strings(normalized)
+
+	return normalized
+}
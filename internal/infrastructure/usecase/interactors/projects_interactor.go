@@ -0,0 +1,129 @@
+package interactors
+
+import (
+	"context"
+	"fmt"
+	"rest_clickhouse/internal/apperrors"
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	"rest_clickhouse/pkg/logger"
+	"rest_clickhouse/pkg/tracing"
+)
+
+const (
+	projectGoodsMaxLimit = 100
+	historyMaxLimit      = 100
+)
+
+// ProjectsInteractor отдает проекты, их товары и историю изменений сразу для нескольких проектов,
+// чтобы вложенные выборки GraphQL укладывались в один запрос на уровень.
+type ProjectsInteractor interface {
+	GetProjects(ctx context.Context, ids []int) ([]*repository.ProjectModel, error)
+	GetGoods(ctx context.Context, query repository.ProjectGoodsQuery) (map[int][]*repository.GoodModel, error)
+	CountGoods(ctx context.Context, projectIds []int) (map[int]*repository.GoodsCountModel, error)
+	GetHistory(ctx context.Context, filter repository.EventsHistoryFilter) ([]*repository.EventsModel, error)
+}
+
+type projectsInteractor struct {
+	projectsRepository repository.ProjectsRepository
+	goodsRepository    repository.GoodsRepository
+	eventsRepository   repository.EventsRepository
+	timeouts           Timeouts
+	logger             logger.Logger
+}
+
+func NewProjectsInteractor(
+	projectsRepository repository.ProjectsRepository,
+	goodsRepository repository.GoodsRepository,
+	eventsRepository repository.EventsRepository,
+	timeouts Timeouts,
+	logger logger.Logger,
+) ProjectsInteractor {
+	return &projectsInteractor{
+		projectsRepository: projectsRepository,
+		goodsRepository:    goodsRepository,
+		eventsRepository:   eventsRepository,
+		timeouts:           timeouts,
+		logger:             logger,
+	}
+}
+
+func (i *projectsInteractor) GetProjects(ctx context.Context, ids []int) ([]*repository.ProjectModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "ProjectsInteractor.GetProjects")
+	defer span.End()
+
+	ctx, cancel := withTimeout(ctx, i.timeouts.Read)
+	defer cancel()
+
+	projects, err := i.projectsRepository.GetByIds(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("error getting projects from repository: %w", err)
+	}
+
+	return projects, nil
+}
+
+func (i *projectsInteractor) GetGoods(ctx context.Context, query repository.ProjectGoodsQuery) (map[int][]*repository.GoodModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "ProjectsInteractor.GetGoods")
+	defer span.End()
+
+	if query.Limit < 1 || query.Limit > projectGoodsMaxLimit {
+		return nil, apperrors.InvalidRequest.WithField("limit", fmt.Sprintf("must be 1-%d", projectGoodsMaxLimit))
+	}
+	if query.Offset < 0 {
+		return nil, apperrors.InvalidRequest.WithField("offset", "must not be negative")
+	}
+
+	ctx, cancel := withTimeout(ctx, i.timeouts.Read)
+	defer cancel()
+
+	goods, err := i.goodsRepository.GetListByProjects(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("error getting goods from repository: %w", err)
+	}
+
+	return goods, nil
+}
+
+// CountGoods возвращает количество товаров каждого из проектов, для проекта без товаров - нули.
+func (i *projectsInteractor) CountGoods(ctx context.Context, projectIds []int) (map[int]*repository.GoodsCountModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "ProjectsInteractor.CountGoods")
+	defer span.End()
+
+	ctx, cancel := withTimeout(ctx, i.timeouts.Read)
+	defer cancel()
+
+	counts, err := i.goodsRepository.CountByProjects(ctx, projectIds)
+	if err != nil {
+		return nil, fmt.Errorf("error counting goods in repository: %w", err)
+	}
+
+	for _, projectId := range projectIds {
+		if _, ok := counts[projectId]; !ok {
+			counts[projectId] = &repository.GoodsCountModel{ProjectId: projectId}
+		}
+	}
+
+	return counts, nil
+}
+
+func (i *projectsInteractor) GetHistory(ctx context.Context, filter repository.EventsHistoryFilter) ([]*repository.EventsModel, error) {
+	ctx, span := tracing.StartSpan(ctx, "ProjectsInteractor.GetHistory")
+	defer span.End()
+
+	if filter.Limit < 1 || filter.Limit > historyMaxLimit {
+		return nil, apperrors.InvalidRequest.WithField("limit", fmt.Sprintf("must be 1-%d", historyMaxLimit))
+	}
+	if len(filter.GoodIds) == 0 && len(filter.ProjectIds) == 0 {
+		return []*repository.EventsModel{}, nil
+	}
+
+	ctx, cancel := withTimeout(ctx, i.timeouts.Search)
+	defer cancel()
+
+	events, err := i.eventsRepository.GetHistory(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("error getting events history from repository: %w", err)
+	}
+
+	return events, nil
+}
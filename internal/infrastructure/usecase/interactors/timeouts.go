@@ -0,0 +1,23 @@
+package interactors
+
+import (
+	"context"
+	"time"
+)
+
+// Timeouts задает дедлайны операций поверх контекста запроса.
+// Нулевое значение означает, что операция ограничена только контекстом вызывающего.
+type Timeouts struct {
+	Read   time.Duration
+	Write  time.Duration
+	Search time.Duration
+}
+
+// withTimeout наследует отмену от ctx, поэтому разрыв соединения клиентом прерывает операцию раньше дедлайна.
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
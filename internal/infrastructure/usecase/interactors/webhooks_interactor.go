@@ -0,0 +1,158 @@
+package interactors
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"rest_clickhouse/internal/api"
+	"rest_clickhouse/internal/apperrors"
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	"rest_clickhouse/pkg/logger"
+	"rest_clickhouse/pkg/netguard"
+)
+
+var ErrInvalidWebhook = apperrors.InvalidWebhook
+
+const webhookSecretBytes = 32
+
+// WebhookDispatcher отправляет доставки подписчикам.
+type WebhookDispatcher interface {
+	Redeliver(delivery *repository.WebhookDeliveryModel, webhook *repository.WebhookModel)
+}
+
+type WebhooksInteractor interface {
+	CreateWebhook(ctx context.Context, webhook *api.Webhook) (*repository.WebhookModel, error)
+	GetList(ctx context.Context, projectId int) ([]*repository.WebhookModel, error)
+	RemoveWebhook(ctx context.Context, id, projectId int) error
+	GetDeliveries(ctx context.Context, webhookId, projectId, limit, offset int) ([]*repository.WebhookDeliveryModel, error)
+	Redeliver(ctx context.Context, deliveryId int64, projectId int) (*repository.WebhookDeliveryModel, error)
+}
+
+type webhooksInteractor struct {
+	webhooksRepository repository.WebhooksRepository
+	dispatcher         WebhookDispatcher
+	timeouts           Timeouts
+	logger             logger.Logger
+}
+
+func NewWebhooksInteractor(webhooksRepository repository.WebhooksRepository, dispatcher WebhookDispatcher, timeouts Timeouts, logger logger.Logger) WebhooksInteractor {
+	return &webhooksInteractor{
+		webhooksRepository: webhooksRepository,
+		dispatcher:         dispatcher,
+		timeouts:           timeouts,
+		logger:             logger,
+	}
+}
+
+func (i *webhooksInteractor) CreateWebhook(ctx context.Context, webhook *api.Webhook) (*repository.WebhookModel, error) {
+	ctx, cancel := withTimeout(ctx, i.timeouts.Write)
+	defer cancel()
+
+	target, err := url.Parse(webhook.Url)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, ErrInvalidWebhook.WithField("url", "must be an absolute http(s) url")
+	}
+	if !netguard.AllowedHost(target.Hostname()) {
+		return nil, ErrInvalidWebhook.WithField("url", "must not point to a loopback, link-local or private address")
+	}
+
+	for _, eventType := range webhook.EventTypes {
+		switch eventType {
+		case repository.GoodCreatedEvent, repository.GoodUpdatedEvent, repository.GoodRemovedEvent:
+		default:
+			return nil, ErrInvalidWebhook.WithField("eventTypes", fmt.Sprintf("unknown event type %q", eventType))
+		}
+	}
+
+	secret := webhook.Secret
+	if secret == "" {
+		secret, err = generateSecret()
+		if err != nil {
+			return nil, fmt.Errorf("error generating secret: %w", err)
+		}
+	}
+
+	webhookModel, err := i.webhooksRepository.Create(ctx, &repository.WebhookModel{
+		ProjectId:  webhook.ProjectId,
+		Url:        webhook.Url,
+		Secret:     secret,
+		EventTypes: webhook.EventTypes,
+		Active:     true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error on create webhook: %w", err)
+	}
+
+	return webhookModel, nil
+}
+
+func (i *webhooksInteractor) GetList(ctx context.Context, projectId int) ([]*repository.WebhookModel, error) {
+	ctx, cancel := withTimeout(ctx, i.timeouts.Read)
+	defer cancel()
+
+	webhooks, err := i.webhooksRepository.GetList(ctx, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("error getting webhooks from repository: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+func (i *webhooksInteractor) RemoveWebhook(ctx context.Context, id, projectId int) error {
+	ctx, cancel := withTimeout(ctx, i.timeouts.Write)
+	defer cancel()
+
+	if err := i.webhooksRepository.Remove(ctx, id, projectId); err != nil {
+		return fmt.Errorf("error on remove webhook: %w", err)
+	}
+
+	return nil
+}
+
+func (i *webhooksInteractor) GetDeliveries(ctx context.Context, webhookId, projectId, limit, offset int) ([]*repository.WebhookDeliveryModel, error) {
+	ctx, cancel := withTimeout(ctx, i.timeouts.Read)
+	defer cancel()
+
+	if _, err := i.webhooksRepository.Get(ctx, webhookId, projectId); err != nil {
+		return nil, fmt.Errorf("error on get webhook: %w", err)
+	}
+
+	deliveries, err := i.webhooksRepository.GetDeliveries(ctx, webhookId, projectId, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("error getting deliveries from repository: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (i *webhooksInteractor) Redeliver(ctx context.Context, deliveryId int64, projectId int) (*repository.WebhookDeliveryModel, error) {
+	ctx, cancel := withTimeout(ctx, i.timeouts.Write)
+	defer cancel()
+
+	delivery, err := i.webhooksRepository.GetDelivery(ctx, deliveryId, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("error on get delivery: %w", err)
+	}
+
+	webhook, err := i.webhooksRepository.Get(ctx, delivery.WebhookId, projectId)
+	if err != nil {
+		return nil, fmt.Errorf("error on get webhook: %w", err)
+	}
+
+	redelivery := *delivery
+	redelivery.Status = repository.DeliveryStatusPending
+	i.dispatcher.Redeliver(&redelivery, webhook)
+
+	return &redelivery, nil
+}
+
+func generateSecret() (string, error) {
+	secret := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(secret), nil
+}
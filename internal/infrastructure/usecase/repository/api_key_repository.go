@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"rest_clickhouse/internal/apperrors"
+	"time"
+)
+
+// ErrApiKeyNotExist объявлена здесь, а не рядом с реализацией: по ней интерактор
+// отличает неизвестный ключ от недоступности хранилища.
+var ErrApiKeyNotExist = apperrors.ApiKeyNotFound
+
+// ApiKeyModel содержит API ключ проекта. Хранится только хэш ключа, Prefix служит для поиска.
+type ApiKeyModel struct {
+	Id         int        `db:"id"`
+	ProjectId  int        `db:"project_id"`
+	Name       string     `db:"name"`
+	Prefix     string     `db:"prefix"`
+	Hash       string     `db:"key_hash"`
+	Role       string     `db:"role"`
+	CreatedAt  time.Time  `db:"created_at"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+	RevokedAt  *time.Time `db:"revoked_at"`
+	// Key - ключ целиком, заполняется только при создании и ротации.
+	Key string `db:"-"`
+}
+
+type ApiKeysRepository interface {
+	Create(ctx context.Context, apiKey *ApiKeyModel) (*ApiKeyModel, error)
+	Get(ctx context.Context, id, projectId int) (*ApiKeyModel, error)
+	GetByPrefix(ctx context.Context, prefix string) (*ApiKeyModel, error)
+	GetList(ctx context.Context, projectId int) ([]*ApiKeyModel, error)
+	Revoke(ctx context.Context, id, projectId int) (*ApiKeyModel, error)
+	Rotate(ctx context.Context, id, projectId int, prefix, hash string) (*ApiKeyModel, error)
+	// UpdateLastUsed сохраняет время последнего использования ключей, id ключа -> время.
+	UpdateLastUsed(ctx context.Context, lastUsed map[int]time.Time) error
+}
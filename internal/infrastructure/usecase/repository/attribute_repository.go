@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	AttributeTypeString  = "string"
+	AttributeTypeNumber  = "number"
+	AttributeTypeBoolean = "boolean"
+	AttributeTypeEnum    = "enum"
+)
+
+// AttributeModel описывает пользовательский атрибут товаров проекта.
+type AttributeModel struct {
+	Id         int       `db:"id"`
+	ProjectId  int       `db:"project_id"`
+	Name       string    `db:"name"`
+	Type       string    `db:"type"`
+	Required   bool      `db:"required"`
+	EnumValues []string  `db:"enum_values"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+type AttributesRepository interface {
+	Create(ctx context.Context, attribute *AttributeModel) (*AttributeModel, error)
+	GetList(ctx context.Context, projectId int) ([]*AttributeModel, error)
+	Remove(ctx context.Context, name string, projectId int) error
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// AuditModel - кто и откуда сделал изменение. Без аутентификации Actor и AuthMethod пусты.
+type AuditModel struct {
+	Actor      string
+	AuthMethod string
+	ClientIP   string
+	UserAgent  string
+	RequestId  string
+}
+
+// AuditRecordModel - событие товара из Clickhouse вместе с его автором.
+type AuditRecordModel struct {
+	AuditModel
+	Id        int
+	ProjectId int
+	Name      string
+	EventType string
+	Removed   bool
+	EventTime time.Time
+}
+
+// AuditFilter задает выборку журнала, нулевые значения полей не ограничивают ее.
+type AuditFilter struct {
+	Actor     string
+	ProjectId int
+	From      time.Time
+	To        time.Time
+	Limit     int
+	Offset    int
+}
+
+type AuditRepository interface {
+	GetList(ctx context.Context, filter AuditFilter) ([]*AuditRecordModel, error)
+}
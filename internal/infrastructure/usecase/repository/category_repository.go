@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// CategoryModel содержит информацию о категории товаров.
+type CategoryModel struct {
+	Id        int       `db:"id"`
+	ProjectId int       `db:"project_id"`
+	ParentId  *int      `db:"parent_id"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func NewCategoryCreateModel(projectId int, parentId *int, name string) *CategoryModel {
+	return &CategoryModel{
+		ProjectId: projectId,
+		ParentId:  parentId,
+		Name:      name,
+	}
+}
+
+type CategoriesRepository interface {
+	Create(ctx context.Context, category *CategoryModel) (*CategoryModel, error)
+	GetList(ctx context.Context, projectId int) ([]*CategoryModel, error)
+	Remove(ctx context.Context, id, projectId int) error
+}
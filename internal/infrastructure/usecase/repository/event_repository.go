@@ -1,29 +1,120 @@
 package repository
 
-import "time"
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	GoodCreatedEvent = "good.created"
+	GoodUpdatedEvent = "good.updated"
+	GoodRemovedEvent = "good.removed"
+)
+
+// GoodEvent публикуется в топик событий: поля товара, тип изменения и кто его сделал.
+type GoodEvent struct {
+	GoodModel
+	AuditModel
+	Type string
+}
 
 type EventsModel struct {
-	Id          int    `json:"id" db:"id"`
-	ProjectId   int    `json:"projectId" db:"project_id"`
-	Name        string `json:"name" db:"name"`
-	Description string `json:"description" db:"description"`
-	Priority    int    `json:"priority" db:"priority"`
-	Removed     bool   `json:"removed" db:"removed"`
+	Id          int               `json:"id" db:"id"`
+	ProjectId   int               `json:"projectId" db:"project_id"`
+	Name        string            `json:"name" db:"name"`
+	Description string            `json:"description" db:"description"`
+	Priority    int               `json:"priority" db:"priority"`
+	Removed     bool              `json:"removed" db:"removed"`
+	Tags        []string          `json:"tags" db:"tags"`
+	Attributes  map[string]string `json:"attributes" db:"attributes"`
+	EventType   string            `json:"eventType" db:"event_type"`
+	Audit       AuditModel
 	EventTime   time.Time
 }
 
-func GoodModelToEvent(goodModel GoodModel) *EventsModel {
+func GoodEventToEvent(event GoodEvent) *EventsModel {
 	return &EventsModel{
-		Id:          goodModel.Id,
-		ProjectId:   goodModel.ProjectId,
-		Name:        goodModel.Name,
-		Description: goodModel.Description,
-		Priority:    goodModel.Priority,
-		Removed:     goodModel.Removed,
+		Id:          event.Id,
+		ProjectId:   event.ProjectId,
+		Name:        event.Name,
+		Description: event.Description,
+		Priority:    event.Priority,
+		Removed:     event.Removed,
+		Tags:        event.Tags,
+		Attributes:  flattenAttributes(event.Attributes),
+		EventType:   event.Type,
+		Audit:       event.AuditModel,
 		EventTime:   time.Now(),
 	}
 }
 
+// flattenAttributes приводит значения атрибутов к строкам для колонки Map(String, String).
+func flattenAttributes(attributes map[string]interface{}) map[string]string {
+	flat := make(map[string]string, len(attributes))
+	for name, value := range attributes {
+		switch v := value.(type) {
+		case string:
+			flat[name] = v
+		case float64:
+			flat[name] = strconv.FormatFloat(v, 'f', -1, 64)
+		case bool:
+			flat[name] = strconv.FormatBool(v)
+		default:
+			data, err := json.Marshal(v)
+			if err != nil {
+				continue
+			}
+			flat[name] = string(data)
+		}
+	}
+
+	return flat
+}
+
+// EventsBatchSettings задает размер пачки событий и интервал ее принудительной записи.
+// Значения можно поменять без перезапуска.
+type EventsBatchSettings struct {
+	size          atomic.Int64
+	flushInterval atomic.Int64
+}
+
+func NewEventsBatchSettings(size int, flushInterval time.Duration) *EventsBatchSettings {
+	settings := &EventsBatchSettings{}
+	settings.SetSize(size)
+	settings.SetFlushInterval(flushInterval)
+
+	return settings
+}
+
+func (s *EventsBatchSettings) Size() int {
+	return int(s.size.Load())
+}
+
+func (s *EventsBatchSettings) SetSize(size int) {
+	s.size.Store(int64(size))
+}
+
+func (s *EventsBatchSettings) FlushInterval() time.Duration {
+	return time.Duration(s.flushInterval.Load())
+}
+
+func (s *EventsBatchSettings) SetFlushInterval(flushInterval time.Duration) {
+	s.flushInterval.Store(int64(flushInterval))
+}
+
+// EventsHistoryFilter выбирает последние события товаров GoodIds или проектов ProjectIds,
+// Limit ограничивает число событий на каждый товар или проект.
+type EventsHistoryFilter struct {
+	GoodIds    []int
+	ProjectIds []int
+	Limit      int
+}
+
 type EventsRepository interface {
-	Create(eventModel *EventsModel) error
+	Create(ctx context.Context, eventModel *EventsModel) error
+	Flush(ctx context.Context) error
+	GetHistory(ctx context.Context, filter EventsHistoryFilter) ([]*EventsModel, error)
 }
@@ -7,13 +7,16 @@ import (
 
 // GoodModel содержит информацию о товаре.
 type GoodModel struct {
-	Id          int       `db:"id"`
-	ProjectId   int       `db:"project_id"`
-	Name        string    `db:"name"`
-	Description string    `db:"description"`
-	Priority    int       `db:"priority"`
-	Removed     bool      `db:"removed"`
-	CreatedAt   time.Time `db:"created_at"`
+	Id          int                    `db:"id"`
+	ProjectId   int                    `db:"project_id"`
+	Name        string                 `db:"name"`
+	Description string                 `db:"description"`
+	Priority    int                    `db:"priority"`
+	Removed     bool                   `db:"removed"`
+	CreatedAt   time.Time              `db:"created_at"`
+	CategoryId  *int                   `db:"category_id"`
+	Tags        []string               `db:"tags"`
+	Attributes  map[string]interface{} `db:"attributes"`
 }
 
 // GoodModelList содержит список товаров и метаданные.
@@ -30,21 +33,108 @@ type Meta struct {
 	Offset  int // Смещение для пагинации
 }
 
-func NewGoodCreateModel(projectId int, name string) *GoodModel {
+// TagModel содержит тег проекта и количество товаров с ним.
+type TagModel struct {
+	Id         int    `db:"id"`
+	ProjectId  int    `db:"project_id"`
+	Name       string `db:"name"`
+	GoodsCount int    `db:"goods_count"`
+}
+
+// GoodListFilter содержит фильтры списка товаров.
+type GoodListFilter struct {
+	ProjectId  int                    // 0 - товары всех проектов
+	Tag        string                 // пустая строка - без фильтра
+	CategoryId int                    // 0 - без фильтра, иначе категория вместе с подкатегориями
+	Attributes map[string]interface{} // товары, атрибуты которых содержат все указанные значения
+}
+
+// GoodSearchModel содержит найденный товар, его релевантность и подсвеченные фрагменты.
+type GoodSearchModel struct {
+	GoodModel
+	Rank                 float64
+	NameHighlight        string
+	DescriptionHighlight string
+}
+
+// GoodSearchModelList содержит результаты поиска и метаданные.
+type GoodSearchModelList struct {
+	Meta  Meta
+	Goods []*GoodSearchModel
+}
+
+// GoodSearchQuery содержит параметры полнотекстового поиска.
+type GoodSearchQuery struct {
+	Query     string
+	ProjectId int // 0 - поиск по всем проектам
+	Limit     int
+	Offset    int
+}
+
+// ProjectGoodsQuery выбирает товары сразу нескольких проектов, Limit и Offset действуют внутри каждого проекта.
+type ProjectGoodsQuery struct {
+	ProjectIds []int
+	Tag        string // пустая строка - без фильтра
+	CategoryId int    // 0 - без фильтра, иначе категория вместе с подкатегориями
+	Limit      int
+	Offset     int
+}
+
+// GoodsCountModel содержит количество товаров проекта.
+type GoodsCountModel struct {
+	ProjectId int
+	Total     int
+	Removed   int
+}
+
+// GoodDefaultPriority - приоритет товара, созданного без приоритета или со сброшенным приоритетом.
+// Совпадает со значением по умолчанию колонки goods.priority.
+const GoodDefaultPriority = 1
+
+func NewGoodCreateModel(projectId int, name, description string, priority int, attributes map[string]interface{}) *GoodModel {
 	return &GoodModel{
 		ProjectId:   projectId,
 		Name:        name,
-		Description: "",
+		Description: description,
+		Priority:    priority,
 		Removed:     false,
+		Attributes:  attributes,
 	}
 }
 
-func NewGoodUpdateModel(id int, projectId int, name string, description string) *GoodModel {
+// GoodUpdateModel - изменение товара, поля со значением nil не меняются.
+type GoodUpdateModel struct {
+	Id          int
+	ProjectId   int
+	Name        *string
+	Description *string
+	Priority    *int
+	Attributes  map[string]interface{}
+	// MergeAttributes вычисляет новые атрибуты из текущих. Вызывается в транзакции обновления
+	// под блокировкой строки, поэтому параллельные изменения атрибутов не теряются. Заменяет Attributes.
+	MergeAttributes func(current map[string]interface{}) (map[string]interface{}, error)
+}
+
+func NewGoodUpdateModel(id int, projectId int) *GoodUpdateModel {
+	return &GoodUpdateModel{
+		Id:        id,
+		ProjectId: projectId,
+	}
+}
+
+func NewGoodTagsModel(id int, projectId int, tags []string) *GoodModel {
 	return &GoodModel{
-		Id:          id,
-		ProjectId:   projectId,
-		Name:        name,
-		Description: description,
+		Id:        id,
+		ProjectId: projectId,
+		Tags:      tags,
+	}
+}
+
+func NewGoodCategoryModel(id int, projectId int, categoryId *int) *GoodModel {
+	return &GoodModel{
+		Id:         id,
+		ProjectId:  projectId,
+		CategoryId: categoryId,
 	}
 }
 
@@ -58,7 +148,14 @@ func NewGoodRemoveModel(id int, projectId int) *GoodModel {
 
 type GoodsRepository interface {
 	Create(ctx context.Context, Good *GoodModel) (*GoodModel, error)
-	GetList(ctx context.Context, limit, offset int) (*GoodModelList, error)
+	Get(ctx context.Context, id, projectId int) (*GoodModel, error)
+	GetList(ctx context.Context, limit, offset int, filter GoodListFilter) (*GoodModelList, error)
+	GetListByProjects(ctx context.Context, query ProjectGoodsQuery) (map[int][]*GoodModel, error)
+	CountByProjects(ctx context.Context, projectIds []int) (map[int]*GoodsCountModel, error)
 	Remove(ctx context.Context, good *GoodModel) (*GoodModel, error)
-	Update(ctx context.Context, good *GoodModel) (*GoodModel, error)
+	Update(ctx context.Context, update *GoodUpdateModel) (*GoodModel, error)
+	Search(ctx context.Context, query GoodSearchQuery) (*GoodSearchModelList, error)
+	SetTags(ctx context.Context, good *GoodModel) (*GoodModel, error)
+	SetCategory(ctx context.Context, good *GoodModel) (*GoodModel, error)
+	GetTags(ctx context.Context, projectId int) ([]*TagModel, error)
 }
@@ -0,0 +1,14 @@
+package repository
+
+import "context"
+
+// ProjectModel содержит информацию о проекте.
+type ProjectModel struct {
+	Id   int    `db:"id"`
+	Name string `db:"name"`
+}
+
+type ProjectsRepository interface {
+	// GetByIds возвращает найденные проекты, отсутствующие идентификаторы пропускаются.
+	GetByIds(ctx context.Context, ids []int) ([]*ProjectModel, error)
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusSucceeded = "succeeded"
+	DeliveryStatusFailed    = "failed"
+)
+
+// WebhookModel содержит подписку проекта на события товаров.
+type WebhookModel struct {
+	Id         int       `db:"id"`
+	ProjectId  int       `db:"project_id"`
+	Url        string    `db:"url"`
+	Secret     string    `db:"secret"`
+	EventTypes []string  `db:"event_types"` // пустой список - все события
+	Active     bool      `db:"active"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+// WebhookDeliveryModel содержит запись журнала доставки события.
+type WebhookDeliveryModel struct {
+	Id           int64      `db:"id"`
+	WebhookId    int        `db:"webhook_id"`
+	ProjectId    int        `db:"project_id"`
+	EventType    string     `db:"event_type"`
+	Payload      []byte     `db:"payload"`
+	Status       string     `db:"status"`
+	Attempts     int        `db:"attempts"`
+	ResponseCode *int       `db:"response_code"`
+	LastError    string     `db:"last_error"`
+	CreatedAt    time.Time  `db:"created_at"`
+	DeliveredAt  *time.Time `db:"delivered_at"`
+}
+
+type WebhooksRepository interface {
+	Create(ctx context.Context, webhook *WebhookModel) (*WebhookModel, error)
+	Get(ctx context.Context, id, projectId int) (*WebhookModel, error)
+	GetList(ctx context.Context, projectId int) ([]*WebhookModel, error)
+	Remove(ctx context.Context, id, projectId int) error
+	GetSubscribed(ctx context.Context, projectId int, eventType string) ([]*WebhookModel, error)
+	CreateDelivery(ctx context.Context, delivery *WebhookDeliveryModel) (*WebhookDeliveryModel, error)
+	UpdateDelivery(ctx context.Context, delivery *WebhookDeliveryModel) error
+	GetDelivery(ctx context.Context, id int64, projectId int) (*WebhookDeliveryModel, error)
+	GetDeliveries(ctx context.Context, webhookId, projectId, limit, offset int) ([]*WebhookDeliveryModel, error)
+	// GetPendingDeliveries возвращает незавершенные доставки активных подписок в порядке создания.
+	GetPendingDeliveries(ctx context.Context) ([]*WebhookDeliveryModel, error)
+}
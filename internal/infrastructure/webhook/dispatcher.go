@@ -0,0 +1,423 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"rest_clickhouse/internal/api"
+	"rest_clickhouse/internal/infrastructure/queue"
+	nats_client "rest_clickhouse/internal/infrastructure/queue/nats"
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	"rest_clickhouse/pkg/logger"
+	"rest_clickhouse/pkg/netguard"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+const (
+	defaultMaxAttempts = 6
+	defaultBackoff     = time.Second
+	maxBackoff         = 5 * time.Minute
+	maxConcurrent      = 16
+	dbTimeout          = 5 * time.Second
+	maxErrorLength     = 1024
+)
+
+// Payload отправляется в теле запроса к подписчику.
+type Payload struct {
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurredAt"`
+	Actor      string    `json:"actor,omitempty"`
+	Data       api.Good  `json:"data"`
+}
+
+// Dispatcher читает топик событий и доставляет их по подпискам проектов
+// с HMAC-подписью и повторами с экспоненциальной задержкой.
+// Каждую доставку в один момент отправляет не больше одной серии попыток.
+type Dispatcher struct {
+	sub                queue.Subscriber
+	webhooksRepository repository.WebhooksRepository
+	client             *http.Client
+	logger             logger.Logger
+	ctx                context.Context
+	cancel             context.CancelFunc
+	unsub              func(ctx context.Context) error
+	stopping           chan struct{}
+
+	maxAttempts int
+	backoff     time.Duration
+	sem         chan struct{}
+	wg          sync.WaitGroup
+
+	// active содержит доставки с запущенной серией попыток, по каналу серия узнает о запросе повторной отправки.
+	mu     sync.Mutex
+	active map[int64]chan struct{}
+}
+
+func NewDispatcher(
+	ctx context.Context,
+	sub queue.Subscriber,
+	webhooksRepository repository.WebhooksRepository,
+	client *http.Client,
+	logger logger.Logger,
+) *Dispatcher {
+	ctx, cancel := context.WithCancel(ctx)
+
+	return &Dispatcher{
+		sub:                sub,
+		webhooksRepository: webhooksRepository,
+		client:             client,
+		logger:             logger,
+		ctx:                ctx,
+		cancel:             cancel,
+		maxAttempts:        defaultMaxAttempts,
+		backoff:            defaultBackoff,
+		sem:                make(chan struct{}, maxConcurrent),
+		stopping:           make(chan struct{}),
+		active:             make(map[int64]chan struct{}),
+	}
+}
+
+// NewHTTPClient возвращает клиент для запросов подписчикам. Соединения с loopback, link-local
+// и частными адресами отклоняются после разрешения имени, прокси из окружения не используется.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: netguard.Control,
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			ForceAttemptHTTP2:   true,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        maxConcurrent,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+func (d *Dispatcher) Start() error {
+	unsub, err := d.sub.Sub(nats_client.EventTopicName, d.handle)
+	if err != nil {
+		return err
+	}
+	d.unsub = unsub
+
+	d.resumePending()
+
+	return nil
+}
+
+// resumePending заново запускает доставки, оставшиеся в статусе pending после прошлой остановки,
+// с новой серией попыток.
+func (d *Dispatcher) resumePending() {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	deliveries, err := d.webhooksRepository.GetPendingDeliveries(ctx)
+	if err != nil {
+		d.logger.ErrorF("error getting pending webhook deliveries: %v", err)
+		return
+	}
+
+	webhooks := make(map[int]*repository.WebhookModel)
+	for _, delivery := range deliveries {
+		webhook, ok := webhooks[delivery.WebhookId]
+		if !ok {
+			webhook, err = d.webhooksRepository.Get(ctx, delivery.WebhookId, delivery.ProjectId)
+			if err != nil {
+				d.logger.ErrorF("error getting webhook %d for pending delivery %d: %v", delivery.WebhookId, delivery.Id, err)
+				continue
+			}
+			webhooks[delivery.WebhookId] = webhook
+		}
+
+		d.run(delivery, webhook)
+	}
+
+	if len(deliveries) > 0 {
+		d.logger.InfoF("resumed %d pending webhook deliveries", len(deliveries))
+	}
+}
+
+// Stop перестает принимать события и ждет отправляемые запросы до истечения ctx, после чего прерывает их.
+// Повторы, ждущие своей попытки, и доставки в очереди не ждутся.
+// Прерванные доставки остаются в статусе pending.
+func (d *Dispatcher) Stop(ctx context.Context) error {
+	d.logger.Info("Stop webhook dispatcher!")
+
+	var err error
+	if d.unsub != nil {
+		err = d.unsub(ctx)
+	}
+	close(d.stopping)
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		d.cancel()
+		<-done
+	}
+	d.cancel()
+
+	return err
+}
+
+// Redeliver повторно отправляет сохраненную доставку, начиная новую серию попыток.
+// Если серия этой доставки еще идет, она не дублируется: ожидающий повтор отправляется сразу,
+// а после последней попытки серия начинается заново.
+func (d *Dispatcher) Redeliver(delivery *repository.WebhookDeliveryModel, webhook *repository.WebhookModel) {
+	delivery.Status = repository.DeliveryStatusPending
+	d.run(delivery, webhook)
+}
+
+func (d *Dispatcher) handle(m *nats.Msg) {
+	var event repository.GoodEvent
+	if err := json.Unmarshal(m.Data, &event); err != nil {
+		d.logger.ErrorF("webhook dispatcher unmarshal error: %v", err)
+		return
+	}
+
+	if event.Type == "" {
+		event.Type = repository.GoodUpdatedEvent
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	webhooks, err := d.webhooksRepository.GetSubscribed(ctx, event.ProjectId, event.Type)
+	if err != nil {
+		d.logger.ErrorF("error getting subscribed webhooks: %v", err)
+		return
+	}
+
+	if len(webhooks) == 0 {
+		return
+	}
+
+	payload, err := json.Marshal(Payload{
+		Type:       event.Type,
+		OccurredAt: time.Now().UTC(),
+		Actor:      event.Actor,
+		Data:       api.GetUpdatedGood(&event.GoodModel),
+	})
+	if err != nil {
+		d.logger.ErrorF("error marshaling webhook payload: %v", err)
+		return
+	}
+
+	for _, webhook := range webhooks {
+		delivery, err := d.webhooksRepository.CreateDelivery(ctx, &repository.WebhookDeliveryModel{
+			WebhookId: webhook.Id,
+			ProjectId: webhook.ProjectId,
+			EventType: event.Type,
+			Payload:   payload,
+			Status:    repository.DeliveryStatusPending,
+		})
+		if err != nil {
+			d.logger.ErrorF("error creating webhook delivery: %v", err)
+			continue
+		}
+
+		d.run(delivery, webhook)
+	}
+}
+
+func (d *Dispatcher) run(delivery *repository.WebhookDeliveryModel, webhook *repository.WebhookModel) {
+	d.mu.Lock()
+	if redeliver, ok := d.active[delivery.Id]; ok {
+		select {
+		case redeliver <- struct{}{}:
+		default:
+		}
+		d.mu.Unlock()
+		return
+	}
+	redeliver := make(chan struct{}, 1)
+	d.active[delivery.Id] = redeliver
+	d.mu.Unlock()
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		defer d.release(delivery.Id, redeliver)
+
+		select {
+		case d.sem <- struct{}{}:
+			defer func() { <-d.sem }()
+		case <-d.stopping:
+			return
+		case <-d.ctx.Done():
+			return
+		}
+
+		d.deliver(delivery, webhook, redeliver)
+	}()
+}
+
+// release снимает отметку о серии, если ее не заняла новая серия той же доставки.
+func (d *Dispatcher) release(id int64, redeliver chan struct{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.active[id] == redeliver {
+		delete(d.active, id)
+	}
+}
+
+// finish завершает серию. Если во время последней попытки запрошена повторная отправка,
+// серия продолжается, иначе доставка снимается с учета под той же блокировкой, чтобы запрос не потерялся.
+func (d *Dispatcher) finish(id int64, redeliver chan struct{}) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	select {
+	case <-redeliver:
+		return false
+	default:
+		delete(d.active, id)
+		return true
+	}
+}
+
+func (d *Dispatcher) deliver(delivery *repository.WebhookDeliveryModel, webhook *repository.WebhookModel, redeliver chan struct{}) {
+	for attempt := 1; ; attempt++ {
+		// Запрос повторной отправки, пришедший до начала попытки, она и выполняет.
+		select {
+		case <-redeliver:
+		default:
+		}
+
+		delivery.Attempts++
+		code, err := d.send(delivery, webhook)
+		delivery.ResponseCode = code
+
+		switch {
+		case err == nil:
+			now := time.Now()
+			delivery.Status = repository.DeliveryStatusSucceeded
+			delivery.LastError = ""
+			delivery.DeliveredAt = &now
+		case attempt >= d.maxAttempts:
+			delivery.Status = repository.DeliveryStatusFailed
+			delivery.LastError = truncate(err.Error(), maxErrorLength)
+		default:
+			delivery.LastError = truncate(err.Error(), maxErrorLength)
+		}
+
+		d.saveDelivery(delivery)
+
+		if delivery.Status != repository.DeliveryStatusPending {
+			if d.finish(delivery.Id, redeliver) {
+				return
+			}
+			delivery.Status = repository.DeliveryStatusPending
+			attempt = 0
+			continue
+		}
+
+		timer := time.NewTimer(d.backoffFor(attempt))
+		select {
+		case <-timer.C:
+		case <-redeliver:
+			timer.Stop()
+			attempt = 0
+		case <-d.stopping:
+			timer.Stop()
+			return
+		case <-d.ctx.Done():
+			timer.Stop()
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) send(delivery *repository.WebhookDeliveryModel, webhook *repository.WebhookModel) (*int, error) {
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(d.ctx, http.MethodPost, webhook.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.EventType)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.Id, 10))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, timestamp, delivery.Payload))
+
+	res, err := d.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %w", err)
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+
+	code := res.StatusCode
+	if code < http.StatusOK || code >= http.StatusMultipleChoices {
+		return &code, fmt.Errorf("unexpected status code %d", code)
+	}
+
+	return &code, nil
+}
+
+func (d *Dispatcher) saveDelivery(delivery *repository.WebhookDeliveryModel) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	if err := d.webhooksRepository.UpdateDelivery(ctx, delivery); err != nil {
+		d.logger.ErrorF("error updating webhook delivery %d: %v", delivery.Id, err)
+	}
+}
+
+func (d *Dispatcher) backoffFor(attempt int) time.Duration {
+	backoff := d.backoff << (attempt - 1)
+	if backoff <= 0 || backoff > maxBackoff {
+		return maxBackoff
+	}
+
+	return backoff
+}
+
+// Sign возвращает подпись тела запроса: sha256=hex(HMAC-SHA256(secret, "<timestamp>.<body>")).
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func truncate(str string, length int) string {
+	if len(str) <= length {
+		return str
+	}
+
+	return str[:length]
+}
@@ -0,0 +1,389 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"rest_clickhouse/internal/api"
+	"rest_clickhouse/internal/infrastructure/usecase/repository"
+	"rest_clickhouse/pkg/logger/zerolog"
+	"rest_clickhouse/pkg/netguard"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+const testSecret = "secret"
+
+type fakeSubscriber struct {
+	fn func(m *nats.Msg)
+}
+
+func (s *fakeSubscriber) Sub(_ string, fn func(m *nats.Msg)) (func(ctx context.Context) error, error) {
+	s.fn = fn
+	return func(context.Context) error { return nil }, nil
+}
+
+func (s *fakeSubscriber) publish(t *testing.T, event repository.GoodEvent) {
+	t.Helper()
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("error marshaling event: %v", err)
+	}
+	s.fn(&nats.Msg{Data: data})
+}
+
+// fakeRepository хранит подписки и доставки в памяти.
+type fakeRepository struct {
+	repository.WebhooksRepository
+
+	mu         sync.Mutex
+	webhook    *repository.WebhookModel
+	deliveries map[int64]repository.WebhookDeliveryModel
+	nextId     int64
+}
+
+func newFakeRepository(url string) *fakeRepository {
+	return &fakeRepository{
+		webhook:    &repository.WebhookModel{Id: 1, ProjectId: 1, Url: url, Secret: testSecret, Active: true},
+		deliveries: make(map[int64]repository.WebhookDeliveryModel),
+	}
+}
+
+func (r *fakeRepository) Get(_ context.Context, id, projectId int) (*repository.WebhookModel, error) {
+	if id != r.webhook.Id || projectId != r.webhook.ProjectId {
+		return nil, errors.New("webhook not exist")
+	}
+
+	return r.webhook, nil
+}
+
+func (r *fakeRepository) GetSubscribed(_ context.Context, projectId int, _ string) ([]*repository.WebhookModel, error) {
+	if projectId != r.webhook.ProjectId {
+		return nil, nil
+	}
+
+	return []*repository.WebhookModel{r.webhook}, nil
+}
+
+func (r *fakeRepository) CreateDelivery(_ context.Context, delivery *repository.WebhookDeliveryModel) (*repository.WebhookDeliveryModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.nextId++
+	created := *delivery
+	created.Id = r.nextId
+	r.deliveries[created.Id] = created
+
+	return &created, nil
+}
+
+func (r *fakeRepository) UpdateDelivery(_ context.Context, delivery *repository.WebhookDeliveryModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.deliveries[delivery.Id] = *delivery
+
+	return nil
+}
+
+func (r *fakeRepository) GetPendingDeliveries(context.Context) ([]*repository.WebhookDeliveryModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var pending []*repository.WebhookDeliveryModel
+	for _, delivery := range r.deliveries {
+		if delivery.Status == repository.DeliveryStatusPending {
+			delivery := delivery
+			pending = append(pending, &delivery)
+		}
+	}
+
+	return pending, nil
+}
+
+func (r *fakeRepository) delivery(id int64) repository.WebhookDeliveryModel {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.deliveries[id]
+}
+
+// receiver - подписчик, отвечающий статусами из statuses по очереди, а после них - 200.
+type receiver struct {
+	t        *testing.T
+	statuses []int
+
+	mu          sync.Mutex
+	requests    []*http.Request
+	inFlight    int
+	maxInFlight int
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	rc.mu.Lock()
+	rc.requests = append(rc.requests, r)
+	n := len(rc.requests)
+	rc.inFlight++
+	rc.maxInFlight = max(rc.maxInFlight, rc.inFlight)
+	rc.mu.Unlock()
+
+	defer func() {
+		rc.mu.Lock()
+		rc.inFlight--
+		rc.mu.Unlock()
+	}()
+
+	timestamp, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		rc.t.Errorf("bad timestamp header %q", r.Header.Get(TimestampHeader))
+	}
+	if got, want := r.Header.Get(SignatureHeader), Sign(testSecret, timestamp, body); got != want {
+		rc.t.Errorf("signature %q, want %q", got, want)
+	}
+	if got := r.Header.Get(EventHeader); got != repository.GoodCreatedEvent {
+		rc.t.Errorf("event header %q, want %q", got, repository.GoodCreatedEvent)
+	}
+
+	var payload Payload
+	if err := json.Unmarshal(body, &payload); err != nil || payload.Data.Id != 7 {
+		rc.t.Errorf("unexpected payload %s: %v", body, err)
+	}
+
+	if n <= len(rc.statuses) {
+		w.WriteHeader(rc.statuses[n-1])
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (rc *receiver) count() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	return len(rc.requests)
+}
+
+func newTestDispatcher(t *testing.T, statuses ...int) (*Dispatcher, *fakeSubscriber, *fakeRepository, *receiver) {
+	t.Helper()
+
+	rc := &receiver{t: t, statuses: statuses}
+	server := httptest.NewServer(rc)
+	t.Cleanup(server.Close)
+
+	log, err := zerolog.NewZeroLog(io.Discard, zerolog.Config{})
+	if err != nil {
+		t.Fatalf("error creating logger: %v", err)
+	}
+
+	sub := &fakeSubscriber{}
+	repo := newFakeRepository(server.URL)
+	d := NewDispatcher(context.Background(), sub, repo, server.Client(), log)
+	d.backoff = time.Millisecond
+	d.maxAttempts = 3
+
+	return d, sub, repo, rc
+}
+
+func stopDispatcher(d *Dispatcher) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_ = d.Stop(ctx)
+}
+
+func testEvent() repository.GoodEvent {
+	return repository.GoodEvent{
+		GoodModel: repository.GoodModel{Id: 7, ProjectId: 1, Name: "good"},
+		Type:      repository.GoodCreatedEvent,
+	}
+}
+
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDispatcherRetriesUntilSuccess(t *testing.T) {
+	d, sub, repo, rc := newTestDispatcher(t, http.StatusInternalServerError, http.StatusBadGateway)
+	if err := d.Start(); err != nil {
+		t.Fatalf("error starting dispatcher: %v", err)
+	}
+	defer stopDispatcher(d)
+
+	sub.publish(t, testEvent())
+
+	waitFor(t, "delivery to succeed", func() bool {
+		return repo.delivery(1).Status == repository.DeliveryStatusSucceeded
+	})
+	delivery := repo.delivery(1)
+	if delivery.Attempts != 3 || rc.count() != 3 {
+		t.Errorf("attempts %d, requests %d, want 3", delivery.Attempts, rc.count())
+	}
+	if delivery.ResponseCode == nil || *delivery.ResponseCode != http.StatusOK || delivery.LastError != "" || delivery.DeliveredAt == nil {
+		t.Errorf("unexpected delivery result: %+v", delivery)
+	}
+	if got := rc.requests[0].Header.Get(DeliveryHeader); got != "1" {
+		t.Errorf("delivery header %q, want 1", got)
+	}
+}
+
+func TestDispatcherFailsAfterMaxAttempts(t *testing.T) {
+	d, sub, repo, rc := newTestDispatcher(t, http.StatusInternalServerError, http.StatusInternalServerError, http.StatusInternalServerError)
+	if err := d.Start(); err != nil {
+		t.Fatalf("error starting dispatcher: %v", err)
+	}
+	defer stopDispatcher(d)
+
+	sub.publish(t, testEvent())
+
+	waitFor(t, "delivery to fail", func() bool {
+		return repo.delivery(1).Status == repository.DeliveryStatusFailed
+	})
+	if delivery := repo.delivery(1); delivery.Attempts != 3 || delivery.LastError == "" || rc.count() != 3 {
+		t.Errorf("unexpected delivery result: %+v, requests %d", delivery, rc.count())
+	}
+}
+
+func TestDispatcherRedeliver(t *testing.T) {
+	d, sub, repo, rc := newTestDispatcher(t)
+	if err := d.Start(); err != nil {
+		t.Fatalf("error starting dispatcher: %v", err)
+	}
+	defer stopDispatcher(d)
+
+	sub.publish(t, testEvent())
+	waitFor(t, "delivery to succeed", func() bool {
+		return repo.delivery(1).Status == repository.DeliveryStatusSucceeded
+	})
+
+	redelivery := repo.delivery(1)
+	d.Redeliver(&redelivery, repo.webhook)
+
+	waitFor(t, "redelivery", func() bool {
+		return repo.delivery(1).Attempts == 2 && repo.delivery(1).Status == repository.DeliveryStatusSucceeded
+	})
+	if rc.count() != 2 {
+		t.Errorf("requests %d, want 2", rc.count())
+	}
+	if got := rc.requests[1].Header.Get(DeliveryHeader); got != "1" {
+		t.Errorf("delivery header %q, want 1", got)
+	}
+}
+
+func TestDispatcherRedeliverJoinsRunningRetry(t *testing.T) {
+	d, sub, repo, rc := newTestDispatcher(t, http.StatusInternalServerError, http.StatusInternalServerError)
+	d.backoff = time.Hour
+	if err := d.Start(); err != nil {
+		t.Fatalf("error starting dispatcher: %v", err)
+	}
+	defer stopDispatcher(d)
+
+	sub.publish(t, testEvent())
+	waitFor(t, "first attempt", func() bool { return repo.delivery(1).Attempts == 1 })
+
+	// Серия ждет повтора час: повторная отправка выполняет его сразу, а не запускает вторую серию.
+	redelivery := repo.delivery(1)
+	d.Redeliver(&redelivery, repo.webhook)
+	waitFor(t, "second attempt", func() bool { return repo.delivery(1).Attempts == 2 })
+
+	redelivery = repo.delivery(1)
+	d.Redeliver(&redelivery, repo.webhook)
+	waitFor(t, "delivery to succeed", func() bool {
+		return repo.delivery(1).Status == repository.DeliveryStatusSucceeded
+	})
+
+	time.Sleep(50 * time.Millisecond)
+	if rc.count() != 3 || repo.delivery(1).Attempts != 3 {
+		t.Errorf("requests %d, attempts %d, want 3", rc.count(), repo.delivery(1).Attempts)
+	}
+	if rc.maxInFlight != 1 {
+		t.Errorf("%d concurrent requests for one delivery", rc.maxInFlight)
+	}
+}
+
+func TestDispatcherResumesPendingDeliveries(t *testing.T) {
+	d, _, repo, rc := newTestDispatcher(t)
+
+	event := testEvent()
+	payload, err := json.Marshal(Payload{Type: event.Type, Data: api.GetUpdatedGood(&event.GoodModel)})
+	if err != nil {
+		t.Fatalf("error marshaling payload: %v", err)
+	}
+	_, _ = repo.CreateDelivery(context.Background(), &repository.WebhookDeliveryModel{
+		WebhookId: repo.webhook.Id,
+		ProjectId: repo.webhook.ProjectId,
+		EventType: repository.GoodCreatedEvent,
+		Payload:   payload,
+		Status:    repository.DeliveryStatusPending,
+		Attempts:  2,
+	})
+
+	if err := d.Start(); err != nil {
+		t.Fatalf("error starting dispatcher: %v", err)
+	}
+	defer stopDispatcher(d)
+
+	waitFor(t, "pending delivery to succeed", func() bool {
+		return repo.delivery(1).Status == repository.DeliveryStatusSucceeded
+	})
+	if rc.count() != 1 || repo.delivery(1).Attempts != 3 {
+		t.Errorf("requests %d, attempts %d", rc.count(), repo.delivery(1).Attempts)
+	}
+}
+
+func TestDispatcherStopDoesNotWaitForBackoff(t *testing.T) {
+	d, sub, repo, _ := newTestDispatcher(t, http.StatusInternalServerError)
+	d.backoff = time.Hour
+	if err := d.Start(); err != nil {
+		t.Fatalf("error starting dispatcher: %v", err)
+	}
+
+	sub.publish(t, testEvent())
+	waitFor(t, "first attempt", func() bool { return repo.delivery(1).Attempts == 1 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	start := time.Now()
+	if err := d.Stop(ctx); err != nil {
+		t.Fatalf("error stopping dispatcher: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("stop took %s", elapsed)
+	}
+	if status := repo.delivery(1).Status; status != repository.DeliveryStatusPending {
+		t.Errorf("status %q, want pending", status)
+	}
+}
+
+func TestHTTPClientRejectsPrivateAddresses(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+	}))
+	defer server.Close()
+
+	_, err := NewHTTPClient(time.Second).Post(server.URL, "application/json", nil)
+	if !errors.Is(err, netguard.ErrForbiddenAddress) {
+		t.Errorf("error %v, want %v", err, netguard.ErrForbiddenAddress)
+	}
+	if requests.Load() != 0 {
+		t.Errorf("loopback server received %d requests", requests.Load())
+	}
+}
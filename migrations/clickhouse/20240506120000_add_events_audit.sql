@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE events
+    ADD COLUMN IF NOT EXISTS event_type LowCardinality(String) DEFAULT '',
+    ADD COLUMN IF NOT EXISTS actor_id String DEFAULT '',
+    ADD COLUMN IF NOT EXISTS auth_method LowCardinality(String) DEFAULT '',
+    ADD COLUMN IF NOT EXISTS client_ip String DEFAULT '',
+    ADD COLUMN IF NOT EXISTS user_agent String DEFAULT '',
+    ADD COLUMN IF NOT EXISTS request_id String DEFAULT '';
+
+ALTER TABLE events ADD INDEX IF NOT EXISTS index_actor_id_events actor_id TYPE bloom_filter GRANULARITY 1;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE events DROP INDEX IF EXISTS index_actor_id_events;
+
+ALTER TABLE events
+    DROP COLUMN IF EXISTS event_type,
+    DROP COLUMN IF EXISTS actor_id,
+    DROP COLUMN IF EXISTS auth_method,
+    DROP COLUMN IF EXISTS client_ip,
+    DROP COLUMN IF EXISTS user_agent,
+    DROP COLUMN IF EXISTS request_id;
+-- +goose StatementEnd
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS goods_name_trgm_idx;
+DROP INDEX IF EXISTS goods_search_vector_idx;
+ALTER TABLE GOODS DROP COLUMN IF EXISTS search_vector;
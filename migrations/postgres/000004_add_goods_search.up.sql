@@ -0,0 +1,10 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE GOODS ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(description, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS goods_search_vector_idx ON GOODS USING GIN(search_vector);
+CREATE INDEX IF NOT EXISTS goods_name_trgm_idx ON GOODS USING GIN(name gin_trgm_ops);
@@ -0,0 +1,6 @@
+BEGIN;
+DROP TABLE GOODS_TAGS;
+DROP TABLE TAGS;
+ALTER TABLE GOODS DROP COLUMN IF EXISTS category_id;
+DROP TABLE CATEGORIES;
+COMMIT;
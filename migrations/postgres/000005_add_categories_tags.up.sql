@@ -0,0 +1,37 @@
+BEGIN;
+CREATE TABLE IF NOT EXISTS CATEGORIES (
+    id serial NOT NULL,
+    project_id int NOT NULL REFERENCES PROJECTS(id) ON DELETE CASCADE,
+    parent_id int REFERENCES CATEGORIES(id) ON DELETE CASCADE,
+    name VARCHAR(256) NOT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY(id)
+    );
+
+CREATE INDEX ON CATEGORIES(project_id);
+CREATE INDEX ON CATEGORIES(parent_id);
+
+ALTER TABLE GOODS ADD COLUMN IF NOT EXISTS category_id int REFERENCES CATEGORIES(id) ON DELETE SET NULL;
+CREATE INDEX ON GOODS(category_id);
+
+CREATE TABLE IF NOT EXISTS TAGS (
+    id serial NOT NULL,
+    project_id int NOT NULL REFERENCES PROJECTS(id) ON DELETE CASCADE,
+    name VARCHAR(64) NOT NULL,
+
+    PRIMARY KEY(id),
+    UNIQUE(project_id, name)
+    );
+
+CREATE TABLE IF NOT EXISTS GOODS_TAGS (
+    good_id int NOT NULL,
+    project_id int NOT NULL,
+    tag_id int NOT NULL REFERENCES TAGS(id) ON DELETE CASCADE,
+
+    PRIMARY KEY(good_id, tag_id),
+    FOREIGN KEY(good_id, project_id) REFERENCES GOODS(id, project_id) ON DELETE CASCADE
+    );
+
+CREATE INDEX ON GOODS_TAGS(tag_id);
+COMMIT;
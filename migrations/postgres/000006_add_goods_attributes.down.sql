@@ -0,0 +1,5 @@
+BEGIN;
+DROP INDEX IF EXISTS goods_attributes_idx;
+ALTER TABLE GOODS DROP COLUMN IF EXISTS attributes;
+DROP TABLE ATTRIBUTE_SCHEMAS;
+COMMIT;
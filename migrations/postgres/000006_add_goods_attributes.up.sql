@@ -0,0 +1,17 @@
+BEGIN;
+CREATE TABLE IF NOT EXISTS ATTRIBUTE_SCHEMAS (
+    id serial NOT NULL,
+    project_id int NOT NULL REFERENCES PROJECTS(id) ON DELETE CASCADE,
+    name VARCHAR(64) NOT NULL,
+    type VARCHAR(16) NOT NULL CHECK (type IN ('string', 'number', 'boolean', 'enum')),
+    required bool NOT NULL DEFAULT false,
+    enum_values TEXT[] NOT NULL DEFAULT '{}',
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY(id),
+    UNIQUE(project_id, name)
+    );
+
+ALTER TABLE GOODS ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}';
+CREATE INDEX IF NOT EXISTS goods_attributes_idx ON GOODS USING GIN(attributes jsonb_path_ops);
+COMMIT;
@@ -0,0 +1,4 @@
+BEGIN;
+DROP TABLE WEBHOOK_DELIVERIES;
+DROP TABLE WEBHOOKS;
+COMMIT;
@@ -0,0 +1,33 @@
+BEGIN;
+CREATE TABLE IF NOT EXISTS WEBHOOKS (
+    id serial NOT NULL,
+    project_id int NOT NULL REFERENCES PROJECTS(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret VARCHAR(128) NOT NULL,
+    event_types TEXT[] NOT NULL DEFAULT '{}',
+    active bool NOT NULL DEFAULT true,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY(id)
+    );
+
+CREATE INDEX ON WEBHOOKS(project_id);
+
+CREATE TABLE IF NOT EXISTS WEBHOOK_DELIVERIES (
+    id bigserial NOT NULL,
+    webhook_id int NOT NULL REFERENCES WEBHOOKS(id) ON DELETE CASCADE,
+    project_id int NOT NULL,
+    event_type VARCHAR(64) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending',
+    attempts int NOT NULL DEFAULT 0,
+    response_code int,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at timestamp,
+
+    PRIMARY KEY(id)
+    );
+
+CREATE INDEX ON WEBHOOK_DELIVERIES(webhook_id, created_at DESC);
+COMMIT;
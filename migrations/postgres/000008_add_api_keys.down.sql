@@ -0,0 +1,3 @@
+BEGIN;
+DROP TABLE API_KEYS;
+COMMIT;
@@ -0,0 +1,17 @@
+BEGIN;
+CREATE TABLE IF NOT EXISTS API_KEYS (
+    id serial NOT NULL,
+    project_id int NOT NULL REFERENCES PROJECTS(id) ON DELETE CASCADE,
+    name VARCHAR(128) NOT NULL,
+    prefix VARCHAR(16) NOT NULL UNIQUE,
+    key_hash VARCHAR(64) NOT NULL,
+    role VARCHAR(16) NOT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at timestamp,
+    revoked_at timestamp,
+
+    PRIMARY KEY(id)
+    );
+
+CREATE INDEX ON API_KEYS(project_id);
+COMMIT;
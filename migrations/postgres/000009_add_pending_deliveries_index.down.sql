@@ -0,0 +1,3 @@
+BEGIN;
+DROP INDEX IF EXISTS webhook_deliveries_pending_idx;
+COMMIT;
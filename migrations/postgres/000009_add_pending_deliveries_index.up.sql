@@ -0,0 +1,3 @@
+BEGIN;
+CREATE INDEX IF NOT EXISTS webhook_deliveries_pending_idx ON WEBHOOK_DELIVERIES(id) WHERE status = 'pending';
+COMMIT;
@@ -0,0 +1,22 @@
+package audit
+
+import "context"
+
+// Client описывает, откуда пришел запрос: адрес и User-Agent вызывающего.
+type Client struct {
+	IP        string
+	UserAgent string
+}
+
+type contextKey struct{}
+
+// ContextWithClient сохраняет адрес и User-Agent вызывающего для журнала изменений.
+func ContextWithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, contextKey{}, client)
+}
+
+// ClientFromContext возвращает данные вызывающего, если они были сохранены.
+func ClientFromContext(ctx context.Context) (Client, bool) {
+	client, ok := ctx.Value(contextKey{}).(Client)
+	return client, ok
+}
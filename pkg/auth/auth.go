@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"errors"
+)
+
+type Role string
+
+const (
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+)
+
+// AllProjects - ключ в claims projects, задающий роль во всех проектах.
+const AllProjects = "*"
+
+const (
+	MethodJWT    = "jwt"
+	MethodApiKey = "api_key"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+var roleRanks = map[Role]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+}
+
+func (r Role) Valid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// Allows сообщает, покрывает ли роль требуемую: editor может все, что viewer, admin - все, что editor.
+func (r Role) Allows(required Role) bool {
+	rank, ok := roleRanks[r]
+	return ok && rank >= roleRanks[required]
+}
+
+// Actor - аутентифицированный вызывающий и его роли по проектам.
+type Actor struct {
+	Id string
+	// Method - способ аутентификации: jwt или api_key.
+	Method   string
+	Projects map[int]Role
+	// AllProjectsRole действует в проектах, для которых роль не задана явно.
+	AllProjectsRole Role
+}
+
+func (a *Actor) Role(projectId int) (Role, bool) {
+	if role, ok := a.Projects[projectId]; ok {
+		return role, true
+	}
+	if a.AllProjectsRole != "" {
+		return a.AllProjectsRole, true
+	}
+
+	return "", false
+}
+
+func (a *Actor) Allows(projectId int, required Role) bool {
+	role, ok := a.Role(projectId)
+	return ok && role.Allows(required)
+}
+
+type actorKey struct{}
+
+func ContextWithActor(ctx context.Context, actor *Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func ActorFromContext(ctx context.Context) (*Actor, bool) {
+	actor, ok := ctx.Value(actorKey{}).(*Actor)
+	return actor, ok
+}
@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+)
+
+// Config задает проверку JWT. Для HS256 нужен Secret, для RS256 - PublicKeyFile (PEM)
+// или JWKSFile; ключ из JWKS выбирается по kid из заголовка токена.
+type Config struct {
+	Algorithm     string
+	Secret        string
+	PublicKeyFile string
+	JWKSFile      string
+	// Issuer и Audience проверяются, если заданы.
+	Issuer   string
+	Audience string
+}
+
+// claims - projects сопоставляет id проекта (или "*") с ролью субъекта в нем.
+type claims struct {
+	jwt.RegisteredClaims
+	Projects map[string]Role `json:"projects"`
+}
+
+type JWTVerifier struct {
+	parser  *jwt.Parser
+	keyFunc jwt.Keyfunc
+}
+
+func NewJWTVerifier(cfg Config) (*JWTVerifier, error) {
+	options := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{cfg.Algorithm}),
+		jwt.WithExpirationRequired(),
+	}
+	if cfg.Issuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		options = append(options, jwt.WithAudience(cfg.Audience))
+	}
+
+	keyFunc, err := newKeyFunc(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JWTVerifier{
+		parser:  jwt.NewParser(options...),
+		keyFunc: keyFunc,
+	}, nil
+}
+
+func newKeyFunc(cfg Config) (jwt.Keyfunc, error) {
+	switch cfg.Algorithm {
+	case AlgorithmHS256:
+		if cfg.Secret == "" {
+			return nil, fmt.Errorf("jwt secret is required for %s", cfg.Algorithm)
+		}
+		secret := []byte(cfg.Secret)
+		return func(*jwt.Token) (interface{}, error) {
+			return secret, nil
+		}, nil
+	case AlgorithmRS256:
+		if cfg.JWKSFile != "" {
+			keys, err := loadJWKS(cfg.JWKSFile)
+			if err != nil {
+				return nil, err
+			}
+			return func(token *jwt.Token) (interface{}, error) {
+				kid, _ := token.Header["kid"].(string)
+				key, ok := keys[kid]
+				if !ok {
+					return nil, fmt.Errorf("unknown key id %q", kid)
+				}
+				return key, nil
+			}, nil
+		}
+		if cfg.PublicKeyFile != "" {
+			key, err := loadPublicKey(cfg.PublicKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			return func(*jwt.Token) (interface{}, error) {
+				return key, nil
+			}, nil
+		}
+		return nil, fmt.Errorf("jwt public key file or jwks file is required for %s", cfg.Algorithm)
+	default:
+		return nil, fmt.Errorf("unsupported jwt algorithm %q", cfg.Algorithm)
+	}
+}
+
+// Verify проверяет подпись и срок действия токена и возвращает субъекта с его ролями.
+func (v *JWTVerifier) Verify(tokenString string) (*Actor, error) {
+	var tokenClaims claims
+	if _, err := v.parser.ParseWithClaims(tokenString, &tokenClaims, v.keyFunc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if tokenClaims.Subject == "" {
+		return nil, fmt.Errorf("%w: subject is required", ErrInvalidToken)
+	}
+
+	actor := &Actor{
+		Id:       tokenClaims.Subject,
+		Method:   MethodJWT,
+		Projects: make(map[int]Role, len(tokenClaims.Projects)),
+	}
+	for project, role := range tokenClaims.Projects {
+		if !role.Valid() {
+			return nil, fmt.Errorf("%w: unknown role %q", ErrInvalidToken, role)
+		}
+		if project == AllProjects {
+			actor.AllProjectsRole = role
+			continue
+		}
+		projectId, err := strconv.Atoi(project)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid project id %q", ErrInvalidToken, project)
+		}
+		actor.Projects[projectId] = role
+	}
+
+	return actor, nil
+}
+
+func loadPublicKey(path string) (*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading jwt public key: %w", err)
+	}
+
+	key, err := jwt.ParseRSAPublicKeyFromPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing jwt public key %s: %w", path, err)
+	}
+
+	return key, nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"rest_clickhouse/configs"
 	"rest_clickhouse/pkg/logger"
+	"rest_clickhouse/pkg/tracing"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -15,7 +16,13 @@ type DB struct {
 }
 
 func NewDBConnection(ctx context.Context, cnf *configs.Config, log logger.Logger) (*DB, error) {
-	db, err := pgxpool.New(ctx, cnf.Postgres.DSN)
+	poolConfig, err := pgxpool.ParseConfig(cnf.Postgres.DSN)
+	if err != nil {
+		return nil, fmt.Errorf("error parse db config: %w", err)
+	}
+	poolConfig.ConnConfig.Tracer = tracing.PgxTracer{}
+
+	db, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
 		return nil, fmt.Errorf("error create new db: %w", err)
 	}
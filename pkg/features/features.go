@@ -0,0 +1,35 @@
+package features
+
+import "sync/atomic"
+
+// Flags включают и выключают отдельные функции сервиса без перезапуска.
+type Flags struct {
+	goodsListCache atomic.Bool
+	goodsStream    atomic.Bool
+}
+
+func NewFlags(goodsListCache, goodsStream bool) *Flags {
+	flags := &Flags{}
+	flags.SetGoodsListCache(goodsListCache)
+	flags.SetGoodsStream(goodsStream)
+
+	return flags
+}
+
+// GoodsListCache - кэшировать ли списки товаров в Redis.
+func (f *Flags) GoodsListCache() bool {
+	return f.goodsListCache.Load()
+}
+
+func (f *Flags) SetGoodsListCache(enabled bool) {
+	f.goodsListCache.Store(enabled)
+}
+
+// GoodsStream - доступна ли SSE подписка на изменения товаров.
+func (f *Flags) GoodsStream() bool {
+	return f.goodsStream.Load()
+}
+
+func (f *Flags) SetGoodsStream(enabled bool) {
+	f.goodsStream.Store(enabled)
+}
@@ -0,0 +1,104 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"rest_clickhouse/pkg/logger"
+	"time"
+)
+
+// Hook описывает запуск и остановку подсистемы. Start не должен блокироваться:
+// долгоживущие серверы запускаются в отдельной горутине. Hook без Start описывает
+// уже открытый ресурс и считается запущенным с момента регистрации.
+type Hook struct {
+	Name  string
+	Start func(ctx context.Context) error
+	Stop  func(ctx context.Context) error
+}
+
+type entry struct {
+	hook    Hook
+	running bool
+}
+
+// Lifecycle запускает подсистемы в порядке регистрации и останавливает в обратном,
+// поэтому подсистема регистрируется после всех, от которых зависит.
+type Lifecycle struct {
+	entries []*entry
+	logger  logger.Logger
+}
+
+func New(logger logger.Logger) *Lifecycle {
+	return &Lifecycle{
+		logger: logger,
+	}
+}
+
+func (l *Lifecycle) Append(hook Hook) {
+	l.entries = append(l.entries, &entry{hook: hook, running: hook.Start == nil})
+}
+
+// Start запускает подсистемы по очереди и прерывается на первой ошибке.
+// Уже запущенные подсистемы остаются запущенными, их останавливает Stop.
+func (l *Lifecycle) Start(ctx context.Context) error {
+	for _, e := range l.entries {
+		if e.running {
+			continue
+		}
+
+		l.logger.InfoF("starting %s", e.hook.Name)
+		if err := e.hook.Start(ctx); err != nil {
+			return fmt.Errorf("error starting %s: %w", e.hook.Name, err)
+		}
+		e.running = true
+	}
+
+	return nil
+}
+
+// Stop останавливает запущенные подсистемы в обратном порядке в пределах общего дедлайна ctx.
+// Шаг, после которого есть другие, получает не больше половины оставшегося времени, поэтому
+// зависшая подсистема не забирает все время у следующих (например, у записи событий в Clickhouse).
+// Ошибка или истекший дедлайн на одном шаге не отменяют остальные шаги: ресурсы закрываются в любом случае.
+func (l *Lifecycle) Stop(ctx context.Context) error {
+	var steps []*entry
+	for i := len(l.entries) - 1; i >= 0; i-- {
+		e := l.entries[i]
+		if !e.running {
+			continue
+		}
+		e.running = false
+		if e.hook.Stop != nil {
+			steps = append(steps, e)
+		}
+	}
+
+	var errs []error
+	for i, e := range steps {
+		l.logger.InfoF("stopping %s", e.hook.Name)
+		start := time.Now()
+		stepCtx, cancel := stepContext(ctx, i == len(steps)-1)
+		err := e.hook.Stop(stepCtx)
+		cancel()
+		if err != nil {
+			l.logger.ErrorF("error stopping %s after %s: %v", e.hook.Name, time.Since(start), err)
+			errs = append(errs, fmt.Errorf("error stopping %s: %w", e.hook.Name, err))
+			continue
+		}
+		l.logger.InfoF("stopped %s in %s", e.hook.Name, time.Since(start))
+	}
+
+	return errors.Join(errs...)
+}
+
+// stepContext ограничивает шаг половиной оставшегося до дедлайна ctx времени. Последний шаг
+// и ctx без дедлайна получают весь оставшийся бюджет.
+func stepContext(ctx context.Context, last bool) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok || last {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, time.Until(deadline)/2)
+}
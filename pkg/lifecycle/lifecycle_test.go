@@ -0,0 +1,91 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"rest_clickhouse/pkg/logger/zerolog"
+	"testing"
+	"time"
+)
+
+func newTestLifecycle(t *testing.T) *Lifecycle {
+	t.Helper()
+
+	log, err := zerolog.NewZeroLog(io.Discard, zerolog.Config{})
+	if err != nil {
+		t.Fatalf("error creating logger: %v", err)
+	}
+
+	return New(log)
+}
+
+// hang имитирует подсистему, которая не может остановиться до истечения дедлайна.
+func hang(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestStopStaysWithinDeadline(t *testing.T) {
+	app := newTestLifecycle(t)
+	for i := 0; i < 14; i++ {
+		app.Append(Hook{Name: "hanging", Stop: hang})
+	}
+
+	const timeout = 200 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := app.Stop(ctx)
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if elapsed > timeout+100*time.Millisecond {
+		t.Errorf("shutdown took %s, want at most %s", elapsed, timeout)
+	}
+}
+
+func TestStopLeavesBudgetAfterHangingStep(t *testing.T) {
+	app := newTestLifecycle(t)
+
+	var lastBudget time.Duration
+	app.Append(Hook{Name: "flush", Stop: func(ctx context.Context) error {
+		deadline, _ := ctx.Deadline()
+		lastBudget = time.Until(deadline)
+		return nil
+	}})
+	app.Append(Hook{Name: "hanging", Stop: hang})
+
+	const timeout = 200 * time.Millisecond
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := app.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error %v, want %v", err, context.DeadlineExceeded)
+	}
+	if lastBudget < timeout/4 {
+		t.Errorf("last step got %s, want at least %s", lastBudget, timeout/4)
+	}
+}
+
+func TestStopReverseOrder(t *testing.T) {
+	app := newTestLifecycle(t)
+
+	var stopped []string
+	for _, name := range []string{"postgres", "nats", "http"} {
+		app.Append(Hook{Name: name, Stop: func(context.Context) error {
+			stopped = append(stopped, name)
+			return nil
+		}})
+	}
+
+	if err := app.Stop(context.Background()); err != nil {
+		t.Fatalf("error stopping: %v", err)
+	}
+	if want := []string{"http", "nats", "postgres"}; len(stopped) != len(want) || stopped[0] != want[0] || stopped[2] != want[2] {
+		t.Errorf("stop order %v, want %v", stopped, want)
+	}
+}
@@ -0,0 +1,39 @@
+package logger
+
+import "context"
+
+const (
+	RequestIdKey = "request_id"
+	ProjectIdKey = "project_id"
+	TraceIdKey   = "trace_id"
+	SpanIdKey    = "span_id"
+)
+
+type contextKey int
+
+const (
+	requestIdContextKey contextKey = iota
+	projectIdContextKey
+)
+
+// ContextWithRequestId сохраняет id запроса для WithContext.
+func ContextWithRequestId(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIdContextKey, requestId)
+}
+
+// RequestIdFromContext возвращает id запроса, если он был сохранен.
+func RequestIdFromContext(ctx context.Context) (string, bool) {
+	requestId, ok := ctx.Value(requestIdContextKey).(string)
+	return requestId, ok && requestId != ""
+}
+
+// ContextWithProjectId сохраняет id проекта для WithContext.
+func ContextWithProjectId(ctx context.Context, projectId int) context.Context {
+	return context.WithValue(ctx, projectIdContextKey, projectId)
+}
+
+// ProjectIdFromContext возвращает id проекта, если он был сохранен.
+func ProjectIdFromContext(ctx context.Context) (int, bool) {
+	projectId, ok := ctx.Value(projectIdContextKey).(int)
+	return projectId, ok
+}
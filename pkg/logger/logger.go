@@ -1,5 +1,7 @@
 package logger
 
+import "context"
+
 type Logger interface {
 	Warn(kv ...interface{})
 	Error(kv ...interface{})
@@ -9,4 +11,13 @@ type Logger interface {
 	ErrorF(str string, kv ...interface{})
 	DebugF(str string, kv ...interface{})
 	InfoF(str string, kv ...interface{})
+
+	// With возвращает логгер, добавляющий к каждой записи пары ключ-значение.
+	With(kv ...interface{}) Logger
+	// WithContext возвращает логгер с полями запроса из контекста: request id, project id и trace id.
+	WithContext(ctx context.Context) Logger
+	// Sampled возвращает логгер для горячих путей, который пропускает часть debug и info записей.
+	Sampled() Logger
+	// SetLevel меняет минимальный уровень записей на лету, в том числе для уже созданных производных логгеров.
+	SetLevel(level string) error
 }
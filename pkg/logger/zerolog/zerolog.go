@@ -1,40 +1,126 @@
 package zerolog
 
 import (
+	"context"
 	"fmt"
 	"io"
+	"rest_clickhouse/pkg/logger"
 	"strings"
 	"time"
 
 	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const (
+	FormatConsole = "console"
+	FormatJSON    = "json"
+)
+
+// Config задает уровень, формат вывода и сэмплирование логгера.
+// SampleEvery - в Sampled пишется каждая N-я debug и info запись, 0 и 1 отключают сэмплирование.
+type Config struct {
+	Level       string
+	Format      string
+	SampleEvery uint32
+}
+
 type ZeroLogWrapper struct {
-	log zerolog.Logger
+	log     zerolog.Logger
+	sampled zerolog.Logger
 }
 
-func NewZeroLog(logWriter io.Writer) (*ZeroLogWrapper, error) {
-	ioWriter := zerolog.ConsoleWriter{
-		Out:        logWriter,
-		TimeFormat: time.RFC3339,
-		FormatLevel: func(i interface{}) string {
-			return strings.ToUpper(fmt.Sprintf("[%s]", i))
-		},
+func NewZeroLog(logWriter io.Writer, cfg Config) (*ZeroLogWrapper, error) {
+	writer := logWriter
+	switch cfg.Format {
+	case FormatConsole, "":
+		writer = zerolog.ConsoleWriter{
+			Out:        logWriter,
+			TimeFormat: time.RFC3339,
+			FormatLevel: func(i interface{}) string {
+				return strings.ToUpper(fmt.Sprintf("[%s]", i))
+			},
+		}
+	case FormatJSON:
+	default:
+		return nil, fmt.Errorf("unknown log format %q", cfg.Format)
 	}
 
-	lvl, err := zerolog.ParseLevel(zerolog.ErrorLevel.String())
-	if err != nil {
+	if err := setGlobalLevel(cfg.Level); err != nil {
 		return nil, err
 	}
 
-	zeroLog := zerolog.New(ioWriter)
-	zeroLog = zeroLog.Level(lvl).With().Timestamp().Logger()
+	zeroLog := zerolog.New(writer).With().Timestamp().Logger()
+
+	sampled := zeroLog
+	if cfg.SampleEvery > 1 {
+		// Сэмплер общий для всех производных логгеров, поэтому счетчик не сбрасывается при With.
+		sampler := &zerolog.BasicSampler{N: cfg.SampleEvery}
+		sampled = zeroLog.Sample(zerolog.LevelSampler{
+			DebugSampler: sampler,
+			InfoSampler:  sampler,
+		})
+	}
 
 	return &ZeroLogWrapper{
-		log: zeroLog,
+		log:     zeroLog,
+		sampled: sampled,
 	}, nil
 }
 
+// SetLevel меняет глобальный уровень zerolog: логгер в процессе один, а уровень производных
+// логгеров, созданных через With, иначе изменить нельзя.
+func (z *ZeroLogWrapper) SetLevel(level string) error {
+	return setGlobalLevel(level)
+}
+
+func setGlobalLevel(level string) error {
+	if level == "" {
+		level = zerolog.InfoLevel.String()
+	}
+	lvl, err := zerolog.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	zerolog.SetGlobalLevel(lvl)
+
+	return nil
+}
+
+func (z *ZeroLogWrapper) With(kv ...interface{}) logger.Logger {
+	if len(kv) == 0 {
+		return z
+	}
+
+	return &ZeroLogWrapper{
+		log:     z.log.With().Fields(kv).Logger(),
+		sampled: z.sampled.With().Fields(kv).Logger(),
+	}
+}
+
+func (z *ZeroLogWrapper) WithContext(ctx context.Context) logger.Logger {
+	kv := make([]interface{}, 0, 8)
+	if requestId, ok := logger.RequestIdFromContext(ctx); ok {
+		kv = append(kv, logger.RequestIdKey, requestId)
+	}
+	if projectId, ok := logger.ProjectIdFromContext(ctx); ok {
+		kv = append(kv, logger.ProjectIdKey, projectId)
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		kv = append(kv, logger.TraceIdKey, spanContext.TraceID().String(), logger.SpanIdKey, spanContext.SpanID().String())
+	}
+
+	return z.With(kv...)
+}
+
+func (z *ZeroLogWrapper) Sampled() logger.Logger {
+	return &ZeroLogWrapper{
+		log:     z.sampled,
+		sampled: z.sampled,
+	}
+}
+
 func (z *ZeroLogWrapper) Warn(kv ...interface{}) {
 	msg := fmt.Sprint(kv...)
 	z.log.Warn().Msg(msg)
@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "goods"
+
+var (
+	HTTPRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Количество HTTP запросов по маршруту и статусу.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Время обработки HTTP запросов по маршруту и статусу.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	HTTPRateLimitedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "rate_limited_total",
+		Help:      "Количество запросов, отклоненных лимитом, scope = client|project.",
+	}, []string{"route", "scope"})
+
+	NatsPublishedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "nats",
+		Name:      "published_total",
+		Help:      "Количество опубликованных сообщений.",
+	}, []string{"subject"})
+
+	NatsPublishErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "nats",
+		Name:      "publish_errors_total",
+		Help:      "Количество ошибок публикации сообщений.",
+	}, []string{"subject"})
+
+	NatsConsumedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "nats",
+		Name:      "consumed_total",
+		Help:      "Количество полученных сообщений.",
+	}, []string{"subject"})
+
+	NatsConsumeErrorsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "nats",
+		Name:      "consume_errors_total",
+		Help:      "Количество ошибок обработки полученных сообщений.",
+	}, []string{"subject"})
+
+	CacheRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "redis",
+		Name:      "cache_requests_total",
+		Help:      "Обращения к кэшу Redis, result = hit|miss|error.",
+	}, []string{"cache", "result"})
+
+	ClickhouseBatchSize = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "clickhouse",
+		Name:      "batch_size",
+		Help:      "Количество событий в одной записи пачки в ClickHouse.",
+		Buckets:   prometheus.LinearBuckets(10, 10, 10),
+	})
+
+	ClickhouseFlushDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "clickhouse",
+		Name:      "flush_duration_seconds",
+		Help:      "Время записи пачки событий в ClickHouse.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	ClickhouseFlushErrorsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "clickhouse",
+		Name:      "flush_errors_total",
+		Help:      "Количество неудачных записей пачек в ClickHouse.",
+	})
+)
+
+const (
+	CacheHit   = "hit"
+	CacheMiss  = "miss"
+	CacheError = "error"
+)
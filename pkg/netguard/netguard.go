@@ -0,0 +1,56 @@
+package netguard
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"syscall"
+)
+
+var ErrForbiddenAddress = errors.New("forbidden address")
+
+// Allowed сообщает, можно ли обращаться к адресу по запросу пользователя: loopback, link-local,
+// частные, неуказанные и multicast адреса закрыты, чтобы через webhooks не ходили во внутреннюю сеть.
+func Allowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+
+	return addr.IsValid() &&
+		!addr.IsLoopback() &&
+		!addr.IsPrivate() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsLinkLocalMulticast() &&
+		!addr.IsInterfaceLocalMulticast() &&
+		!addr.IsMulticast() &&
+		!addr.IsUnspecified()
+}
+
+// Control проверяет адрес соединения для net.Dialer уже после разрешения имени,
+// поэтому имя, указывающее на внутренний адрес, тоже отклоняется.
+func Control(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("error parsing address %s: %w", address, err)
+	}
+	if !Allowed(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addrPort.Addr())
+	}
+
+	return nil
+}
+
+// AllowedHost проверяет хост из URL до разрешения имени: IP адрес должен быть разрешен, localhost запрещен.
+// Остальные имена проверяются при соединении через Control.
+func AllowedHost(host string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return false
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return true
+	}
+
+	return Allowed(addr)
+}
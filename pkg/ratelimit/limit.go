@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Limit - емкость корзины токенов и окно, за которое она полностью восполняется.
+// Записывается как "100/1m": до 100 запросов подряд и в среднем 100 запросов в минуту.
+type Limit struct {
+	Requests int
+	Window   time.Duration
+}
+
+func (l Limit) String() string {
+	return fmt.Sprintf("%d/%s", l.Requests, l.Window)
+}
+
+// ParseLimit разбирает лимит вида "100/1m".
+func ParseLimit(value string) (Limit, error) {
+	requests, window, ok := strings.Cut(strings.TrimSpace(value), "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid limit %q, expected <requests>/<window>", value)
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(requests))
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("invalid limit %q: requests must be a positive integer", value)
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(window))
+	if err != nil || d < time.Millisecond {
+		return Limit{}, fmt.Errorf("invalid limit %q: window must be at least 1ms", value)
+	}
+
+	return Limit{Requests: n, Window: d}, nil
+}
+
+// ParseRules разбирает список правил вида "GET /goods/search=60/1m; apikey:12=500/1m".
+// Пустая строка - пустой список.
+func ParseRules(value string) (map[string]Limit, error) {
+	rules := make(map[string]Limit)
+	for _, rule := range strings.Split(value, ";") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		key, limit, ok := strings.Cut(rule, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid rule %q, expected <key>=<limit>", rule)
+		}
+
+		parsed, err := ParseLimit(limit)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", key, err)
+		}
+		rules[key] = parsed
+	}
+
+	return rules, nil
+}
+
+// Policy задает лимиты. Client действует на каждого вызывающего, Project - на все запросы проекта.
+// Routes переопределяет лимит вызывающего для маршрута ("METHOD /path"), Overrides - для конкретного
+// вызывающего ("apikey:12", "user:user-1", "ip:10.0.0.1") или проекта ("project:3").
+type Policy struct {
+	Enabled   bool
+	Client    Limit
+	Project   Limit
+	Routes    map[string]Limit
+	Overrides map[string]Limit
+}
+
+// Settings хранит действующую политику, ее можно заменить без перезапуска.
+type Settings struct {
+	policy atomic.Pointer[Policy]
+}
+
+func NewSettings(policy Policy) *Settings {
+	settings := &Settings{}
+	settings.Store(policy)
+
+	return settings
+}
+
+func (s *Settings) Load() *Policy {
+	return s.policy.Load()
+}
+
+func (s *Settings) Store(policy Policy) {
+	s.policy.Store(&policy)
+}
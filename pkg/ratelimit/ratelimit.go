@@ -0,0 +1,209 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"rest_clickhouse/pkg/logger"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-redis/redis"
+)
+
+// Result - решение по запросу и состояние корзины для заголовков RateLimit-*.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset - через сколько корзина восполнится полностью.
+	Reset time.Duration
+	// RetryAfter - через сколько появится токен, если запрос отклонен.
+	RetryAfter time.Duration
+}
+
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// tokenBucket атомарно восполняет корзину по прошедшему времени и списывает токен.
+// Возвращает 1/0 и оставшиеся токены строкой, потому что Redis обрезает дробные числа в ответе.
+var tokenBucket = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1]) or capacity
+local ts = tonumber(bucket[2]) or now
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HMSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(capacity / rate))
+
+return {allowed, tostring(tokens)}
+`)
+
+// RedisLimiter - корзина токенов в Redis, общая для всех экземпляров сервиса.
+type RedisLimiter struct {
+	client *redis.Client
+	prefix string
+}
+
+func NewRedisLimiter(client *redis.Client, prefix string) *RedisLimiter {
+	return &RedisLimiter{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+func (l *RedisLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	// Клиент redis v6 не прерывает команды по контексту, поэтому отмененный запрос не идет в redis.
+	if err := ctx.Err(); err != nil {
+		return Result{}, err
+	}
+
+	rate := refillRate(limit)
+	now := time.Now().UnixMilli()
+	reply, err := tokenBucket.Run(l.client.WithContext(ctx), []string{l.prefix + key}, limit.Requests, rate, now).Result()
+	if err != nil {
+		return Result{}, fmt.Errorf("error running rate limit script: %w", err)
+	}
+
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 2 {
+		return Result{}, fmt.Errorf("unexpected rate limit script reply %v", reply)
+	}
+	allowed, _ := values[0].(int64)
+	tokensValue, _ := values[1].(string)
+	tokens, err := strconv.ParseFloat(tokensValue, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("unexpected rate limit script reply %v", reply)
+	}
+
+	return newResult(allowed == 1, tokens, limit), nil
+}
+
+type bucket struct {
+	tokens float64
+	ts     int64
+}
+
+// MemoryLimiter - корзина токенов в памяти процесса. Лимиты действуют на каждый экземпляр отдельно.
+type MemoryLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	calls   int
+}
+
+// memorySweepEvery - раз в сколько вызовов удалять корзины, которые уже восполнились.
+const memorySweepEvery = 1024
+
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{
+		buckets: make(map[string]*bucket),
+	}
+}
+
+func (l *MemoryLimiter) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now().UnixMilli()
+	l.calls++
+	if l.calls%memorySweepEvery == 0 {
+		l.sweep(now)
+	}
+
+	rate := refillRate(limit)
+	capacity := float64(limit.Requests)
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, ts: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(capacity, b.tokens+float64(max(0, now-b.ts))*rate)
+	b.ts = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+
+	return newResult(allowed, b.tokens, limit), nil
+}
+
+// sweep удаляет корзины, не использовавшиеся дольше часа: к этому времени они восполнены
+// при любом разумном окне, и удаление равносильно полной корзине.
+func (l *MemoryLimiter) sweep(now int64) {
+	for key, b := range l.buckets {
+		if now-b.ts > time.Hour.Milliseconds() {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// FallbackLimiter использует Redis, а при его недоступности - лимиты в памяти,
+// чтобы сбой Redis не отключал ограничение и не ронял запросы.
+type FallbackLimiter struct {
+	primary  Limiter
+	fallback Limiter
+	logger   logger.Logger
+	degraded atomic.Bool
+}
+
+func NewFallbackLimiter(primary, fallback Limiter, logger logger.Logger) *FallbackLimiter {
+	return &FallbackLimiter{
+		primary:  primary,
+		fallback: fallback,
+		logger:   logger,
+	}
+}
+
+func (l *FallbackLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	result, err := l.primary.Allow(ctx, key, limit)
+	if err == nil {
+		if l.degraded.CompareAndSwap(true, false) {
+			l.logger.Info("rate limiter is back to redis")
+		}
+		return result, nil
+	}
+	if ctx.Err() != nil {
+		return result, err
+	}
+
+	// Логируем только переход, иначе при недоступном Redis будет запись на каждый запрос.
+	if l.degraded.CompareAndSwap(false, true) {
+		l.logger.WithContext(ctx).WarnF("rate limiter falls back to memory: %v", err)
+	}
+
+	return l.fallback.Allow(ctx, key, limit)
+}
+
+// refillRate - скорость восполнения в токенах за миллисекунду.
+func refillRate(limit Limit) float64 {
+	return float64(limit.Requests) / float64(limit.Window.Milliseconds())
+}
+
+func newResult(allowed bool, tokens float64, limit Limit) Result {
+	rate := refillRate(limit)
+	result := Result{
+		Allowed:   allowed,
+		Limit:     limit.Requests,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration((float64(limit.Requests)-tokens)/rate) * time.Millisecond,
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration(math.Ceil((1-tokens)/rate)) * time.Millisecond
+	}
+
+	return result
+}
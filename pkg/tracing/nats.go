@@ -0,0 +1,24 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/nats-io/nats.go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// InjectNatsHeader записывает контекст трейса в заголовки сообщения NATS.
+func InjectNatsHeader(ctx context.Context, header nats.Header) {
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(header))
+}
+
+// ExtractNatsHeader восстанавливает контекст трейса из заголовков сообщения NATS.
+func ExtractNatsHeader(ctx context.Context, header nats.Header) context.Context {
+	if header == nil {
+		return ctx
+	}
+
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(http.Header(header)))
+}